@@ -8,20 +8,363 @@
 package JTStools
 
 import (
+	"bufio"
+	"bytes"
+	"encoding"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"log"
+	"io"
+	"log/slog"
+	"math"
+	"os"
 	"reflect"
+	"runtime/debug"
+	"sort"
 	"strconv"
 	"strings"
+	"sync"
+	"time"
+	"unicode"
 )
 
+// FieldError 单个字段的转换错误
+type FieldError struct {
+	Path       string       //字段的完整路径，由各层的标签名（多个key时为第一个）或字段名以及下标组成，如 school.subject[2].Score，根节点为空
+	SourceKind reflect.Kind //源数据类型
+	TargetType reflect.Type //目标结构体字段类型
+	Err        error        //原始错误
+}
+
+// Error 实现error接口，缺少字段、null、key有歧义时没有发生类型转换，不输出源数据类型和目标类型
+func (e *FieldError) Error() string {
+	msg := e.Err.Error()
+	if e.TargetType != nil && !errors.Is(e.Err, ErrRequired) && !errors.Is(e.Err, ErrNull) && !errors.Is(e.Err, ErrAmbiguousKey) {
+		msg = fmt.Sprintf("cannot transform %s into %s: %s", e.SourceKind, e.TargetType, msg)
+	}
+	if e.Path != "" {
+		msg = e.Path + ": " + msg
+	}
+	return msg
+}
+
+// Unwrap 返回原始错误
+func (e *FieldError) Unwrap() error {
+	return e.Err
+}
+
+// TransformError Transform返回的错误，包含每个失败字段的信息
+type TransformError struct {
+	Errors []*FieldError
+}
+
+// Error 实现error接口
+func (e *TransformError) Error() string {
+	msgs := make([]string, 0, len(e.Errors))
+	for _, fe := range e.Errors {
+		msgs = append(msgs, fe.Error())
+	}
+	return strings.Join(msgs, ",")
+}
+
+// Unwrap 返回所有字段错误，支持errors.Is/errors.As
+func (e *TransformError) Unwrap() []error {
+	errs := make([]error, 0, len(e.Errors))
+	for _, fe := range e.Errors {
+		errs = append(errs, fe)
+	}
+	return errs
+}
+
+// PanicError 转换过程中捕获的panic
+type PanicError struct {
+	Value interface{} //panic的值
+	Stack []byte      //panic时的调用栈，CaptureStack为true时记录
+}
+
+// Error 实现error接口
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("panic: %v", e.Value)
+}
+
+// Unwrap panic的值是error时返回该error
+func (e *PanicError) Unwrap() error {
+	err, _ := e.Value.(error)
+	return err
+}
+
+// ErrUnsupportedConversion 源数据类型无法转换成结构体字段类型
+var ErrUnsupportedConversion = errors.New("unsupported conversion")
+
+// ErrorPolicy 字段转换失败时的处理策略
+type ErrorPolicy int
+
+const (
+	ErrorPolicyIgnore   ErrorPolicy = iota //忽略转换失败的字段，只输出日志（默认）
+	ErrorPolicyCollect                     //继续转换其他字段，结束后返回所有转换失败的字段
+	ErrorPolicyFailFast                    //遇到第一个错误立即停止转换
+)
+
+// ErrAmbiguousKey 没有标签名的字段按NameMatcher匹配到源数据中多个key，或带exclusive选项的字段源数据中存在多个key
+var ErrAmbiguousKey = errors.New("ambiguous key")
+
+// ErrRequired 带required选项的字段在源数据中不存在
+var ErrRequired = errors.New("required field missing")
+
+// ErrNull 源数据为null，NullPolicyStrict下不能设置为nil的字段返回该错误
+var ErrNull = errors.New("null value")
+
+// NullPolicy 源数据为null时非指针、切片、map、interface字段的处理策略，字段可以用标签选项 null=ignore|zero|strict 单独指定。
+// 指针、切片、map、interface字段始终设置为nil
+type NullPolicy int
+
+const (
+	NullPolicyIgnore NullPolicy = iota //字段保持原值（默认）
+	NullPolicyZero                     //字段设置为零值
+	NullPolicyStrict                   //返回ErrNull错误
+)
+
+// nullPolicies 标签选项 null 的取值
+var nullPolicies = map[string]NullPolicy{
+	"ignore": NullPolicyIgnore,
+	"zero":   NullPolicyZero,
+	"strict": NullPolicyStrict,
+}
+
+// ErrArrayLength 源数据切片长度和数组字段长度不一致
+var ErrArrayLength = errors.New("array length mismatch")
+
+// ArrayPolicy 源数据切片长度和数组字段长度不一致时的处理策略，字段可以用标签选项 array=loose|truncate|pad|strict 单独指定
+type ArrayPolicy int
+
+const (
+	ArrayPolicyLoose    ArrayPolicy = iota //超出数组长度的元素忽略，不足时剩余元素置为零值（默认）
+	ArrayPolicyTruncate                    //超出数组长度的元素忽略，不足时报错
+	ArrayPolicyZeroPad                     //不足时剩余元素置为零值，超出时报错
+	ArrayPolicyStrict                      //长度不一致时报错
+)
+
+// arrayPolicies 标签选项 array 的取值
+var arrayPolicies = map[string]ArrayPolicy{
+	"loose":    ArrayPolicyLoose,
+	"truncate": ArrayPolicyTruncate,
+	"pad":      ArrayPolicyZeroPad,
+	"strict":   ArrayPolicyStrict,
+}
+
+// NameMatcher 没有标签名的字段在源数据中查找key的规则，fieldName为结构体字段名，key为源数据的key，匹配时返回true
+type NameMatcher func(fieldName string, key string) bool
+
+// MatchCaseInsensitive 忽略大小写匹配，与encoding/json一致，如 UserName 匹配 username
+func MatchCaseInsensitive(fieldName string, key string) bool {
+	return strings.EqualFold(fieldName, key)
+}
+
+// MatchSnakeCase 匹配snake_case，如 UserID 匹配 user_id
+func MatchSnakeCase(fieldName string, key string) bool {
+	return key == strings.ToLower(strings.Join(splitWords(fieldName), "_"))
+}
+
+// MatchScreamingSnake 匹配SCREAMING_SNAKE，如 UserID 匹配 USER_ID
+func MatchScreamingSnake(fieldName string, key string) bool {
+	return key == strings.ToUpper(strings.Join(splitWords(fieldName), "_"))
+}
+
+// MatchKebabCase 匹配kebab-case，如 UserID 匹配 user-id
+func MatchKebabCase(fieldName string, key string) bool {
+	return key == strings.ToLower(strings.Join(splitWords(fieldName), "-"))
+}
+
+// MatchCamelCase 匹配camelCase，如 UserID 匹配 userID、userId
+func MatchCamelCase(fieldName string, key string) bool {
+	words := splitWords(fieldName)
+	if len(words) == 0 {
+		return false
+	}
+	first := strings.ToLower(words[0])
+	title := first
+	for _, w := range words[1:] {
+		r := []rune(w)
+		title += string(unicode.ToUpper(r[0])) + strings.ToLower(string(r[1:]))
+	}
+	return key == first+strings.Join(words[1:], "") || key == title
+}
+
+// MatchAny 任意一个规则匹配即可
+func MatchAny(matchers ...NameMatcher) NameMatcher {
+	return func(fieldName string, key string) bool {
+		for _, match := range matchers {
+			if match(fieldName, key) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// splitWords 按大小写和下划线拆分字段名，如 HTTPServerID 拆分为 HTTP、Server、ID
+func splitWords(name string) []string {
+	var words []string
+	runes := []rune(name)
+	start := 0
+	for k := 0; k <= len(runes); k++ {
+		if k == len(runes) || runes[k] == '_' {
+			if k > start {
+				words = append(words, string(runes[start:k]))
+			}
+			start = k + 1
+			continue
+		}
+		if k == start || !unicode.IsUpper(runes[k]) {
+			continue
+		}
+		//小写或数字后的大写字母，连续大写字母中后面跟小写字母的那个
+		if !unicode.IsUpper(runes[k-1]) || k+1 < len(runes) && unicode.IsLower(runes[k+1]) {
+			words = append(words, string(runes[start:k]))
+			start = k
+		}
+	}
+	return words
+}
+
+// Optional 可以区分源数据中未传、传了null、传了值的字段类型，如PATCH接口中区分age传了0和没有传age
+type Optional[T any] struct {
+	Value   T    //字段值
+	Present bool //源数据中是否有该字段，与Presence一致，为null、空字符串或转换失败时也为true
+	Null    bool //源数据是否为null
+}
+
+// Get 源数据中有该字段且不为null时返回字段值和true
+func (o Optional[T]) Get() (T, bool) {
+	return o.Value, o.Present && !o.Null
+}
+
+// MarshalJSON 未传或为null时编码成null
+func (o Optional[T]) MarshalJSON() ([]byte, error) {
+	if !o.Present || o.Null {
+		return []byte("null"), nil
+	}
+	return json.Marshal(o.Value)
+}
+
+func (o Optional[T]) optionalState() (value interface{}, present bool, null bool) {
+	return o.Value, o.Present, o.Null
+}
+
+func (o *Optional[T]) optionalValue() reflect.Value {
+	return reflect.ValueOf(&o.Value).Elem()
+}
+
+func (o *Optional[T]) setPresent(null bool) {
+	var zero T
+	o.Present = true
+	o.Null = null
+	o.Value = zero
+}
+
+// optionalField *Optional[T]实现的接口，映射时写入
+type optionalField interface {
+	optionalValue() reflect.Value
+	setPresent(null bool)
+}
+
+// optionalReader Optional[T]实现的接口，转换成map时读取
+type optionalReader interface {
+	optionalState() (value interface{}, present bool, null bool)
+}
+
+// Presence 源数据中出现的结构体字段路径，值表示该字段是否为null。
+// 路径与FieldError.Path相同，字段有多个key或按NameMatcher匹配时使用第一个key或字段名；字段是否出现与转换是否成功无关
+type Presence map[string]bool
+
+// Has 源数据中是否有该路径的字段，为null时也返回true
+func (p Presence) Has(path string) bool {
+	_, ok := p[path]
+	return ok
+}
+
+// IsNull 源数据中该路径的字段是否为null
+func (p Presence) IsNull(path string) bool {
+	return p[path]
+}
+
+// Logger 日志接口，args为slog风格的key/value对，*slog.Logger可以直接使用
+type Logger interface {
+	Debug(msg string, args ...any)
+	Warn(msg string, args ...any)
+}
+
+// NewSlogLogger 使用slog输出日志，l为nil时使用slog.Default()
+func NewSlogLogger(l *slog.Logger) Logger {
+	if l == nil {
+		l = slog.Default()
+	}
+	return l
+}
+
+// nopLogger 不输出任何日志
+type nopLogger struct{}
+
+func (nopLogger) Debug(msg string, args ...any) {}
+func (nopLogger) Warn(msg string, args ...any)  {}
+
+// NopLogger 返回不输出任何日志的Logger
+func NopLogger() Logger {
+	return nopLogger{}
+}
+
+// ConverterFunc 自定义转换函数，返回值需要能赋值或转换成目标类型，返回nil时设置为零值
+type ConverterFunc func(src interface{}) (interface{}, error)
+
+// converterKey 转换函数的注册key，sourceKind为reflect.Invalid时匹配任意源数据类型
+type converterKey struct {
+	sourceKind reflect.Kind
+	targetType reflect.Type
+}
+
+// globalConverters 全局转换函数
+var globalConverters = struct {
+	sync.RWMutex
+	fns map[converterKey]ConverterFunc
+}{fns: map[converterKey]ConverterFunc{}}
+
+// RegisterConverter 注册全局转换函数，所有MapToStruct转换targetType类型时优先使用
+func RegisterConverter(targetType reflect.Type, fn ConverterFunc) {
+	RegisterKindConverter(reflect.Invalid, targetType, fn)
+}
+
+// RegisterKindConverter 注册全局转换函数，源数据类型为sourceKind时转换targetType类型优先使用。
+// json中的数字为reflect.Float64，src为float64，UseNumber为true时src为json.Number；
+// Go数据源中的值按原类型匹配并原样传入，如int为reflect.Int
+func RegisterKindConverter(sourceKind reflect.Kind, targetType reflect.Type, fn ConverterFunc) {
+	globalConverters.Lock()
+	defer globalConverters.Unlock()
+	globalConverters.fns[converterKey{sourceKind, targetType}] = fn
+}
+
 // MapToStruct map转struct
 type MapToStruct struct {
-	Debug   bool   //调试模式
-	Success bool   //是否转换成功
-	Tagkey  string //结构体标签名
-	errmsg  string //错误信息
+	Debug        bool           //调试模式
+	Success      bool           //是否转换成功
+	Tagkey       string         //结构体标签名
+	Tagkeys      []string       //按顺序查找的结构体标签名，使用字段上第一个存在的标签，不为空时代替Tagkey
+	ErrorPolicy  ErrorPolicy    //字段转换失败时的处理策略
+	ArrayPolicy  ArrayPolicy    //源数据切片长度和数组字段长度不一致时的处理策略
+	NullPolicy   NullPolicy     //源数据为null时非指针、切片、map、interface字段的处理策略
+	NameMatcher  NameMatcher    //没有标签名的字段在源数据中查找key的规则，为nil时只按字段名精确匹配
+	Logger       Logger         //日志输出，为nil时不输出（调试模式下输出到标准错误）
+	CaptureStack bool           //捕获panic时是否记录调用栈
+	UseNumber    bool           //json解码时数字使用json.Number，整数不会因转换成float64丢失精度
+	TimeLayout   string         //time.Time字段默认的时间格式，为空时使用RFC3339
+	TimeLocation *time.Location //time.Time字段默认的时区，为nil时使用UTC
+	converters   map[converterKey]ConverterFunc
+	errmsg       string //错误信息
+
+	path     string          //当前层级在源数据中的路径
+	errs     *TransformError //错误集合，递归层级之间共享
+	presence Presence        //源数据中出现的字段路径，递归层级之间共享
+	fails    int             //转换失败或忽略转换的次数
+	failPath string          //最近一次转换失败或忽略转换的路径，指针据此决定是否保留新建的对象
 
 	structTypeOf  reflect.Type
 	structTofElem reflect.Type
@@ -31,6 +374,11 @@ type MapToStruct struct {
 	sourceMapData interface{} //map源数据
 }
 
+// Presence 最近一次转换中源数据出现的结构体字段路径，如 school.subject[2].name
+func (m *MapToStruct) Presence() Presence {
+	return m.presence
+}
+
 // getErrmsg 获取错误
 func (m *MapToStruct) GetErrmsg() string {
 	return m.errmsg
@@ -44,744 +392,1666 @@ func NewMapToStruct() *MapToStruct {
 	return m
 }
 
-// clone本结构体对象
-func (m *MapToStruct) cloneMapToStruct() *MapToStruct {
+// RegisterConverter 注册只对本对象生效的转换函数，转换targetType类型时优先于全局转换函数使用
+func (m *MapToStruct) RegisterConverter(targetType reflect.Type, fn ConverterFunc) {
+	m.RegisterKindConverter(reflect.Invalid, targetType, fn)
+}
+
+// RegisterKindConverter 注册只对本对象生效的转换函数，源数据类型为sourceKind时转换targetType类型优先使用
+func (m *MapToStruct) RegisterKindConverter(sourceKind reflect.Kind, targetType reflect.Type, fn ConverterFunc) {
+	if m.converters == nil {
+		m.converters = map[converterKey]ConverterFunc{}
+	}
+	m.converters[converterKey{sourceKind, targetType}] = fn
+}
+
+// converter 查找转换函数，顺序为：本对象指定源数据类型、本对象任意源数据类型、全局指定源数据类型、全局任意源数据类型
+func (m *MapToStruct) converter(sourceKind reflect.Kind, targetType reflect.Type) ConverterFunc {
+	if fn, ok := m.converters[converterKey{sourceKind, targetType}]; ok {
+		return fn
+	}
+	if fn, ok := m.converters[converterKey{reflect.Invalid, targetType}]; ok {
+		return fn
+	}
+	globalConverters.RLock()
+	defer globalConverters.RUnlock()
+	if fn, ok := globalConverters.fns[converterKey{sourceKind, targetType}]; ok {
+		return fn
+	}
+	return globalConverters.fns[converterKey{reflect.Invalid, targetType}]
+}
+
+// hasConverter 是否为targetType注册了转换函数
+func (m *MapToStruct) hasConverter(targetType reflect.Type) bool {
+	for k := range m.converters {
+		if k.targetType == targetType {
+			return true
+		}
+	}
+	globalConverters.RLock()
+	defer globalConverters.RUnlock()
+	for k := range globalConverters.fns {
+		if k.targetType == targetType {
+			return true
+		}
+	}
+	return false
+}
+
+// setConverted 使用转换函数转换后写入dst，转换成功返回true
+func (m *MapToStruct) setConverted(dst reflect.Value, mapVal interface{}, fn ConverterFunc, fieldPath string) bool {
+	out, err := fn(mapVal)
+	if err != nil {
+		m.coerceFail(fieldPath, sourceKind(mapVal), dst.Type(), err)
+		return false
+	}
+	if out == nil {
+		dst.Set(reflect.Zero(dst.Type()))
+		return true
+	}
+	rv := reflect.ValueOf(out)
+	switch {
+	case rv.Type().AssignableTo(dst.Type()):
+		dst.Set(rv)
+	case rv.Type().ConvertibleTo(dst.Type()):
+		dst.Set(rv.Convert(dst.Type()))
+	default:
+		m.coerceFail(fieldPath, sourceKind(mapVal), dst.Type(), fmt.Errorf("converter returned %s", rv.Type()))
+		return false
+	}
+	return true
+}
+
+// clone本结构体对象，path为新对象在源数据中的路径
+func (m *MapToStruct) cloneMapToStruct(path string) *MapToStruct {
 	n := &MapToStruct{}
 	n.Tagkey = m.Tagkey
+	n.Tagkeys = m.Tagkeys
 	n.Debug = m.Debug
+	n.ErrorPolicy = m.ErrorPolicy
+	n.ArrayPolicy = m.ArrayPolicy
+	n.NullPolicy = m.NullPolicy
+	n.NameMatcher = m.NameMatcher
+	n.Logger = m.Logger
+	n.CaptureStack = m.CaptureStack
+	n.UseNumber = m.UseNumber
+	n.TimeLayout = m.TimeLayout
+	n.TimeLocation = m.TimeLocation
+	n.converters = m.converters
+	n.path = path
+	n.errs = m.errs
+	n.presence = m.presence
 	return n
 }
 
-// 获取map的值
-func (m *MapToStruct) getMapValue(i int) (mapVal interface{}, ok bool, tagName string) {
+// joinPath 拼接字段路径
+func joinPath(parent string, key string) string {
+	if parent == "" {
+		return key
+	}
+	return parent + "." + key
+}
+
+// indexPath 拼接切片元素路径
+func indexPath(parent string, index int) string {
+	return fmt.Sprintf("%s[%d]", parent, index)
+}
+
+// sourceKind 查找转换函数和记录错误时源数据的类型，json.Number视为reflect.Float64
+func sourceKind(v interface{}) reflect.Kind {
+	if _, ok := v.(json.Number); ok {
+		return reflect.Float64
+	}
+	return kindOf(v)
+}
+
+// kindOf 获取值的类型，nil返回reflect.Invalid
+func kindOf(v interface{}) reflect.Kind {
+	if v == nil {
+		return reflect.Invalid
+	}
+	return reflect.TypeOf(v).Kind()
+}
+
+// debugLogger 调试模式下没有设置Logger时使用，输出到标准错误
+var debugLogger Logger = slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelDebug}))
+
+// logger 获取日志输出
+func (m *MapToStruct) logger() Logger {
+	if m.Logger != nil {
+		return m.Logger
+	}
+	if m.Debug {
+		return debugLogger
+	}
+	return nopLogger{}
+}
+
+// debug 调试模式下输出调试信息
+func (m *MapToStruct) debug(msg string, args ...any) {
+	if m.Debug {
+		m.logger().Debug(msg, args...)
+	}
+}
+
+// logAttrs 字段相关的日志属性
+func logAttrs(path string, sourceKind reflect.Kind, targetKind reflect.Kind, args ...any) []any {
+	return append([]any{"path", path, "source_kind", sourceKind.String(), "target_kind", targetKind.String()}, args...)
+}
+
+// recoverPanic 捕获panic并记录为path处的错误，必须直接使用defer调用
+func (m *MapToStruct) recoverPanic(path string, sourceKind reflect.Kind, targetType reflect.Type) {
+	if r := recover(); r != nil {
+		pe := &PanicError{Value: r}
+		if m.CaptureStack {
+			pe.Stack = debug.Stack()
+		}
+		m.addError(path, sourceKind, targetType, pe)
+	}
+}
+
+// addError 记录一个字段错误
+func (m *MapToStruct) addError(path string, sourceKind reflect.Kind, targetType reflect.Type, err error) {
+	m.markFail(path)
+	m.errs.Errors = append(m.errs.Errors, &FieldError{
+		Path:       path,
+		SourceKind: sourceKind,
+		TargetType: targetType,
+		Err:        err,
+	})
+}
+
+// coerceFail 字段值转换失败，按ErrorPolicy忽略或记录错误
+func (m *MapToStruct) coerceFail(path string, sourceKind reflect.Kind, targetType reflect.Type, err error) {
+	if m.ErrorPolicy == ErrorPolicyIgnore {
+		m.markFail(path)
+		m.logger().Warn("字段转换失败,忽略转换", logAttrs(path, sourceKind, targetType.Kind(), "error", err.Error())...)
+		return
+	}
+	m.addError(path, sourceKind, targetType, err)
+}
+
+// skipEmpty 源数据为空字符串，忽略转换
+func (m *MapToStruct) skipEmpty(path string, sourceKind reflect.Kind, targetKind reflect.Kind) {
+	m.markFail(path)
+	m.logger().Warn("字段为空,忽略转换", logAttrs(path, sourceKind, targetKind)...)
+}
+
+// markFail 记录转换失败或忽略转换的路径
+func (m *MapToStruct) markFail(path string) {
+	m.fails++
+	m.failPath = path
+}
+
+// converted fails为转换前的失败次数，path处的值是否转换成功，下层路径的失败不影响
+func (m *MapToStruct) converted(fails int, path string) bool {
+	return m.fails == fails || m.failPath != path
+}
+
+// aborted 快速失败模式下已经出现错误，需要停止转换
+func (m *MapToStruct) aborted() bool {
+	return m.ErrorPolicy == ErrorPolicyFailFast && len(m.errs.Errors) > 0
+}
+
+// tagOptions 结构体标签中名称之后的选项，如 stm:"founded,layout=2006-01-02" 中的 layout=2006-01-02
+type tagOptions map[string]string
+
+// Has 是否有该选项
+func (o tagOptions) Has(name string) bool {
+	_, ok := o[name]
+	return ok
+}
+
+// Get 获取选项的值
+func (o tagOptions) Get(name string) string {
+	return o[name]
+}
+
+// tagOptionNames 支持的标签选项名，用于区分新的选项和上一个选项值中逗号之后的内容
+var tagOptionNames = map[string]bool{
+	"omitempty": true,
+	"string":    true,
+	"inline":    true,
+	"required":  true,
+	"exclusive": true,
+	"default":   true,
+	"layout":    true,
+	"tz":        true,
+	"unit":      true,
+	"array":     true,
+	"null":      true,
+}
+
+// parseTag 解析结构体标签，返回名称和选项。选项以逗号分隔，key=value形式的选项值中可以包含逗号，
+// 如 layout=Jan 2, 2006、default=red,blue，逗号之后是支持的选项名时作为新的选项
+func parseTag(tag string) (string, tagOptions) {
+	parts := strings.Split(tag, ",")
+	opts := tagOptions{}
+	last := ""
+	for _, part := range parts[1:] {
+		k, v, hasValue := strings.Cut(part, "=")
+		k = strings.TrimSpace(k)
+		if last != "" && !tagOptionNames[k] {
+			//上一个选项的值中包含逗号
+			opts[last] += "," + part
+			continue
+		}
+		if hasValue {
+			last = k
+			opts[k] = v
+		} else {
+			last = ""
+			opts[k] = ""
+		}
+	}
+	return parts[0], opts
+}
+
+// tagValue 获取结构体字段的标签，Tagkeys不为空时按顺序使用字段上第一个存在的标签，否则使用Tagkey标签
+func (m *MapToStruct) tagValue(field reflect.StructField) string {
+	if len(m.Tagkeys) == 0 {
+		return field.Tag.Get(m.Tagkey)
+	}
+	for _, key := range m.Tagkeys {
+		if tag, ok := field.Tag.Lookup(key); ok {
+			return tag
+		}
+	}
+	return ""
+}
+
+// fieldTag 解析结构体字段的标签
+func (m *MapToStruct) fieldTag(field reflect.StructField) (string, tagOptions) {
+	return parseTag(m.tagValue(field))
+}
+
+// derefType 指针指向的类型，多级指针取最终指向的类型
+func derefType(t reflect.Type) reflect.Type {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t
+}
+
+// fieldIgnored 字段标签为"-"时忽略该字段，"-,"表示map key为"-"
+func (m *MapToStruct) fieldIgnored(field reflect.StructField) bool {
+	return m.tagValue(field) == "-"
+}
+
+// fieldInline 字段是否展开到上层结构体，带inline选项的结构体字段以及没有标签名的匿名结构体字段展开
+func (m *MapToStruct) fieldInline(field reflect.StructField) bool {
+	t := derefType(field.Type)
+	if t.Kind() != reflect.Struct || m.isSpecialType(t) {
+		return false
+	}
+	tagName, opts := m.fieldTag(field)
+	return opts.Has("inline") || field.Anonymous && tagName == ""
+}
+
+// fieldKey 获取结构体字段对应的map key，优先使用标签名，没有标签时使用字段名。标签名有多个key时返回第一个
+func (m *MapToStruct) fieldKey(field reflect.StructField) string {
 	//取tag名
-	tagName = m.structTofElem.Field(i).Tag.Get(m.Tagkey)
-	if tagName != "" {
-		mapVal, ok = m.sourceMapData.(map[string]interface{})[tagName] //取map对应结构体tagName的值
-	} else {
-		//todo 结构体字段tag名称这里没有对字段名做任何转换和结构体字段名保持一致（可以对字段名进行多次转换后在尝试取map中的值）
-		tagName = m.structTofElem.Field(i).Name
-		mapVal, ok = m.sourceMapData.(map[string]interface{})[tagName]
+	if tagName, _ := m.fieldTag(field); tagName != "" {
+		tagName, _, _ = strings.Cut(tagName, "|")
+		return tagName
 	}
-	return mapVal, ok, tagName
+	return field.Name
 }
 
-// Transform 把map映射到结构体
-func (m *MapToStruct) Transform(destStructData interface{}, sourceData interface{}) {
-	defer func() {
-		//捕获异常
-		if err := recover(); err != nil {
-			m.Success = false
-			if len(m.errmsg) == 0 {
-				m.errmsg = err.(string)
-			} else {
-				m.errmsg = fmt.Sprintf("%s,捕获异常:%s", m.errmsg, err.(string))
+// 获取map的值，标签名可以是以|分隔的多个key，没有标签名的字段精确匹配不到时按NameMatcher查找，
+// 匹配到多个key时返回ErrAmbiguousKey错误
+func (m *MapToStruct) getMapValue(i int) (mapVal interface{}, ok bool, err error) {
+	field := m.structTofElem.Field(i)
+	name, opts := m.fieldTag(field)
+	tagName := m.fieldKey(field)
+	sourceMap := m.sourceMapData.(map[string]interface{})
+
+	//多个key使用第一个存在的，带exclusive选项时存在多个key返回错误
+	if strings.Contains(name, "|") {
+		var found []string
+		for _, key := range strings.Split(name, "|") {
+			if val, exist := lookupKey(sourceMap, key); exist {
+				if len(found) == 0 {
+					mapVal, ok = val, true
+				}
+				found = append(found, key)
 			}
 		}
-		//如果是调试模式，输出错误
-		if m.Debug && (len(m.errmsg) > 0) {
-			log.Println(m.errmsg)
+		if len(found) > 1 && opts.Has("exclusive") {
+			return nil, false, fmt.Errorf("%w: %s", ErrAmbiguousKey, strings.Join(found, ", "))
+		}
+		return mapVal, ok, nil
+	}
+
+	mapVal, ok = lookupKey(sourceMap, tagName) //取map对应结构体tagName的值
+	if ok || name != "" || m.NameMatcher == nil {
+		return mapVal, ok, nil
+	}
+
+	//按规则匹配源数据的key
+	var keys []string
+	for key := range sourceMap {
+		if m.NameMatcher(field.Name, key) {
+			keys = append(keys, key)
+		}
+	}
+	switch len(keys) {
+	case 0:
+		return nil, false, nil
+	case 1:
+		return sourceMap[keys[0]], true, nil
+	default:
+		sort.Strings(keys)
+		return nil, false, fmt.Errorf("%w: %s", ErrAmbiguousKey, strings.Join(keys, ", "))
+	}
+}
+
+// lookupKey 获取源数据中key的值，key是嵌套路径且没有同名的key时逐级查找
+func lookupKey(sourceMap map[string]interface{}, key string) (interface{}, bool) {
+	if val, ok := sourceMap[key]; ok {
+		return val, true
+	}
+	if strings.ContainsAny(key, ".[") {
+		return lookupPath(sourceMap, key)
+	}
+	return nil, false
+}
+
+// lookupPath 按路径逐级查找源数据中的值，路径如 profile.contact.mobile、items[0].id、matrix[1][0]
+func lookupPath(src interface{}, path string) (interface{}, bool) {
+	for _, part := range strings.Split(path, ".") {
+		key, rest, hasIndex := strings.Cut(part, "[")
+		if key == "" && !hasIndex {
+			return nil, false
+		}
+		if key != "" {
+			sourceMap, ok := src.(map[string]interface{})
+			if !ok {
+				return nil, false
+			}
+			if src, ok = sourceMap[key]; !ok {
+				return nil, false
+			}
 		}
-	}()
+		//数组下标
+		for hasIndex {
+			var index string
+			var found bool
+			if index, rest, found = strings.Cut(rest, "]"); !found {
+				return nil, false
+			}
+			n, err := strconv.Atoi(index)
+			sourceSli, ok := src.([]interface{})
+			if err != nil || !ok || n < 0 || n >= len(sourceSli) {
+				return nil, false
+			}
+			src = sourceSli[n]
+			rest, hasIndex = strings.CutPrefix(rest, "[")
+		}
+		if rest != "" {
+			return nil, false
+		}
+	}
+	return src, true
+}
 
+// Transform 把map映射到结构体，失败时返回*TransformError。
+// sourceData可以是json串、[]byte，或任意key可以转换成字符串的map以及任意切片、数组，
+// 如yaml库(gopkg.in/yaml.v2、v3)解码到interface{}得到的数据
+func (m *MapToStruct) Transform(destStructData interface{}, sourceData interface{}) error {
+	//非json文本的数据源统一转换成json解码后的格式
+	switch sourceData.(type) {
+	case string, []byte:
+	default:
+		//已经是json解码后结构的数据直接使用
+		if !isDecodedJSON(sourceData) {
+			sourceData = normalizeSource(sourceData)
+		}
+	}
+	return m.run(destStructData, sourceData)
+}
+
+// run 重置状态后执行转换
+func (m *MapToStruct) run(destStructData interface{}, sourceData interface{}) error {
 	//重置状态
 	m.Success = false
 	m.errmsg = ""
+	m.path = ""
+	m.errs = &TransformError{}
+	m.presence = Presence{}
+
+	m.transform(destStructData, sourceData)
+
+	if len(m.errs.Errors) > 0 {
+		m.errmsg = m.errs.Error()
+		//如果是调试模式，输出错误
+		m.debug("转换失败", "error", m.errmsg)
+		return m.errs
+	}
+	m.Success = true
+	return nil
+}
+
+// unmarshal json解码，UseNumber为true时数字解码为json.Number
+func (m *MapToStruct) unmarshal(data []byte, v *interface{}) error {
+	if !m.UseNumber {
+		return json.Unmarshal(data, v)
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	if err := dec.Decode(v); err != nil {
+		return err
+	}
+	//与json.Unmarshal一样不允许多余的内容
+	if _, err := dec.Token(); err != io.EOF {
+		return errors.New("invalid character after top-level value")
+	}
+	return nil
+}
+
+// numberValue 把json.Number转换成适合目标类型的值：
+// 整数目标保留数字原文按字符串解析以免丢失精度，字符串目标使用数字原文，interface目标保持json.Number，其他目标转换成float64
+func numberValue(num json.Number, targetType reflect.Type) interface{} {
+	for targetType.Kind() == reflect.Ptr {
+		targetType = targetType.Elem()
+	}
+	switch targetType.Kind() {
+	case reflect.Interface:
+		//与encoding/json一致，interface保持json.Number
+		return num
+	case reflect.String:
+		return num.String()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uintptr, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if !strings.ContainsAny(num.String(), ".eE") {
+			return num.String()
+		}
+	}
+	if f64, err := num.Float64(); err == nil {
+		return f64
+	}
+	return num.String()
+}
+
+// isDecodedJSON 数据是否已经是json解码后的结构：map[string]interface{}、[]interface{}和非容器类型的值，是时不需要转换
+func isDecodedJSON(v interface{}) bool {
+	switch val := v.(type) {
+	case map[string]interface{}:
+		for _, mv := range val {
+			if !isDecodedJSON(mv) {
+				return false
+			}
+		}
+		return true
+	case []interface{}:
+		for _, sv := range val {
+			if !isDecodedJSON(sv) {
+				return false
+			}
+		}
+		return true
+	}
+	switch reflect.ValueOf(v).Kind() {
+	case reflect.Map, reflect.Slice, reflect.Array, reflect.Ptr:
+		return false
+	}
+	return true
+}
+
+// normalizeSource 把任意Go数据转换成json解码后的结构：map转换成map[string]interface{}，
+// 切片、数组转换成[]interface{}，指针取指向的值，其他值保持原类型，映射时由sourceValue按目标类型转换
+func normalizeSource(v interface{}) interface{} {
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if rv.IsNil() {
+			return nil
+		}
+		return normalizeSource(rv.Elem().Interface())
+	case reflect.Map:
+		if rv.IsNil() {
+			return nil
+		}
+		n := make(map[string]interface{}, rv.Len())
+		iter := rv.MapRange()
+		for iter.Next() {
+			n[fmt.Sprint(iter.Key().Interface())] = normalizeSource(iter.Value().Interface())
+		}
+		return n
+	case reflect.Slice, reflect.Array:
+		if rv.Kind() == reflect.Slice && rv.IsNil() {
+			return nil
+		}
+		n := make([]interface{}, rv.Len())
+		for k := 0; k < rv.Len(); k++ {
+			n[k] = normalizeSource(rv.Index(k).Interface())
+		}
+		return n
+	default:
+		return v
+	}
+}
+
+// sourceValue 把Go数据源中的值转换成json解码后的类型：整数转换成json.Number以保留精度，时间转换成RFC3339字符串，
+// 基础类型定义的类型转换成基础类型。interface目标保持原类型，time.Time目标的时间保持原值由setTime处理以保留时区
+func sourceValue(v interface{}, targetType reflect.Type) interface{} {
+	targetType = derefType(targetType)
+	if targetType.Kind() == reflect.Interface {
+		return v
+	}
+	switch val := v.(type) {
+	case nil, string, bool, float64, json.Number, map[string]interface{}, []interface{}:
+		return v
+	case time.Time:
+		if targetType == timeType {
+			return v
+		}
+		return val.Format(time.RFC3339Nano)
+	}
+
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.String:
+		return rv.String()
+	case reflect.Bool:
+		return rv.Bool()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return json.Number(strconv.FormatInt(rv.Int(), 10))
+	case reflect.Uint, reflect.Uintptr, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return json.Number(strconv.FormatUint(rv.Uint(), 10))
+	case reflect.Float32, reflect.Float64:
+		return rv.Float()
+	default:
+		return v
+	}
+}
+
+// StructToMap 把结构体转换成map，map key与Transform使用相同的Tagkey规则，
+// 嵌套结构体、指针、切片、map递归转换
+func (m *MapToStruct) StructToMap(srcStructData interface{}) (map[string]interface{}, error) {
+	rv := reflect.Indirect(reflect.ValueOf(srcStructData))
+	if rv.Kind() != reflect.Struct {
+		return nil, errors.New("param srcStructData is not struct")
+	}
+	return m.structToMap(rv), nil
+}
+
+// StructToJSON 把结构体转换成json，map key与Transform使用相同的Tagkey规则
+func (m *MapToStruct) StructToJSON(srcStructData interface{}) ([]byte, error) {
+	data, err := m.StructToMap(srcStructData)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(data)
+}
+
+// structToMap 把结构体转换成map
+func (m *MapToStruct) structToMap(rv reflect.Value) map[string]interface{} {
+	rt := rv.Type()
+	data := make(map[string]interface{}, rt.NumField())
+	for i := 0; i < rt.NumField(); i++ {
+		field := rt.Field(i)
+		//跳过未导出以及标签为"-"的字段
+		if !field.IsExported() || m.fieldIgnored(field) {
+			continue
+		}
+		//展开的字段合并到上层
+		if m.fieldInline(field) {
+			if fv := reflect.Indirect(rv.Field(i)); fv.IsValid() {
+				for k, v := range m.structToMap(fv) {
+					data[k] = v
+				}
+			}
+			continue
+		}
+		_, opts := m.fieldTag(field)
+		//omitempty选项，跳过零值
+		if opts.Has("omitempty") && isEmptyValue(rv.Field(i)) {
+			continue
+		}
+		//Optional[T] 未传的字段跳过，*Optional[T]为nil时按普通指针编码成null
+		if opt, ok := rv.Field(i).Interface().(optionalReader); ok && !(rv.Field(i).Kind() == reflect.Ptr && rv.Field(i).IsNil()) {
+			value, present, null := opt.optionalState()
+			if present {
+				data[m.fieldKey(field)] = nil
+				if !null && value != nil {
+					data[m.fieldKey(field)] = m.toMapValue(reflect.ValueOf(value))
+				}
+			}
+			continue
+		}
+		value := m.toMapValue(rv.Field(i))
+		//string选项，数字、布尔值编码成字符串，字符串编码成json字符串
+		if opts.Has("string") {
+			switch fv := reflect.ValueOf(value); fv.Kind() {
+			case reflect.String:
+				value = strconv.Quote(fv.String())
+			case reflect.Bool:
+				value = strconv.FormatBool(fv.Bool())
+			case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+				value = strconv.FormatInt(fv.Int(), 10)
+			case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+				value = strconv.FormatUint(fv.Uint(), 10)
+			case reflect.Float32, reflect.Float64:
+				value = strconv.FormatFloat(fv.Float(), 'g', -1, fv.Type().Bits())
+			}
+		}
+		data[m.fieldKey(field)] = value
+	}
+	return data
+}
+
+// isEmptyValue 值是否为omitempty选项忽略的空值，与encoding/json一致
+func isEmptyValue(rv reflect.Value) bool {
+	switch rv.Kind() {
+	case reflect.Array, reflect.Map, reflect.Slice, reflect.String:
+		return rv.Len() == 0
+	case reflect.Bool:
+		return !rv.Bool()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return rv.Int() == 0
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return rv.Uint() == 0
+	case reflect.Float32, reflect.Float64:
+		return rv.Float() == 0
+	case reflect.Interface, reflect.Ptr:
+		return rv.IsNil()
+	}
+	return false
+}
+
+// isMarshaler 值是否实现了json.Marshaler或encoding.TextMarshaler
+func isMarshaler(rv reflect.Value) bool {
+	switch rv.Interface().(type) {
+	case json.Marshaler, encoding.TextMarshaler:
+		return true
+	}
+	return false
+}
+
+// toMapValue 把结构体字段的值转换成map中的值
+func (m *MapToStruct) toMapValue(rv reflect.Value) interface{} {
+	switch rv.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if rv.IsNil() {
+			return nil
+		}
+		return m.toMapValue(rv.Elem())
+	case reflect.Struct:
+		//时间类型以及实现了编码接口的类型保持原值
+		if isMarshaler(rv) {
+			return rv.Interface()
+		}
+		return m.structToMap(rv)
+	case reflect.Map:
+		if rv.IsNil() {
+			return nil
+		}
+		data := make(map[string]interface{}, rv.Len())
+		iter := rv.MapRange()
+		for iter.Next() {
+			data[fmt.Sprint(iter.Key().Interface())] = m.toMapValue(iter.Value())
+		}
+		return data
+	case reflect.Slice, reflect.Array:
+		if rv.Kind() == reflect.Slice && rv.IsNil() {
+			return nil
+		}
+		//[]byte以及实现了编码接口的类型保持原值
+		if rv.Type().Elem().Kind() == reflect.Uint8 || isMarshaler(rv) {
+			return rv.Interface()
+		}
+		data := make([]interface{}, rv.Len())
+		for k := 0; k < rv.Len(); k++ {
+			data[k] = m.toMapValue(rv.Index(k))
+		}
+		return data
+	default:
+		return rv.Interface()
+	}
+}
+
+// Decoder 从输入流中读取json并映射到结构体，转换配置与MapToStruct相同
+type Decoder struct {
+	*MapToStruct
+	dec *json.Decoder
+}
+
+// NewDecoder 创建从r读取json的Decoder
+func NewDecoder(r io.Reader) *Decoder {
+	return &Decoder{
+		MapToStruct: NewMapToStruct(),
+		dec:         json.NewDecoder(r),
+	}
+}
+
+// More 输入流中是否还有未读取的json值
+func (d *Decoder) More() bool {
+	return d.dec.More()
+}
+
+// Decode 从输入流读取下一个json值并映射到destData，输入流结束时返回io.EOF
+func (d *Decoder) Decode(destData interface{}) error {
+	if d.UseNumber {
+		d.dec.UseNumber()
+	}
+	var sourceData interface{}
+	if err := d.dec.Decode(&sourceData); err != nil {
+		d.Success = false
+		d.errmsg = err.Error()
+		return err
+	}
+	return d.run(destData, sourceData)
+}
+
+// TransformLines 逐行读取NDJSON(JSON Lines)并映射到newRecord创建的对象，每行转换后调用fn，
+// line为从1开始的行号，err为该行的转换错误，fn返回false时停止读取；空行跳过。返回读取输入流的错误
+func (m *MapToStruct) TransformLines(r io.Reader, newRecord func() interface{}, fn func(rec interface{}, line int, err error) bool) error {
+	br := bufio.NewReader(r)
+	for line := 1; ; line++ {
+		data, err := br.ReadBytes('\n')
+		if err != nil && err != io.EOF {
+			return err
+		}
+		if len(bytes.TrimSpace(data)) > 0 {
+			rec := newRecord()
+			if !fn(rec, line, m.Transform(rec, data)) {
+				return nil
+			}
+		}
+		if err == io.EOF {
+			return nil
+		}
+	}
+}
+
+// transform 把map映射到结构体，递归调用时错误记录到共享的错误集合
+func (m *MapToStruct) transform(destStructData interface{}, sourceData interface{}) {
+	//捕获异常
+	defer m.recoverPanic(m.path, kindOf(sourceData), reflect.TypeOf(destStructData))
+
 	if destStructData == nil {
-		m.errmsg = "param destStructData is nil"
+		m.addError(m.path, kindOf(sourceData), nil, errors.New("param destStructData is nil"))
 		return
 	}
 	if sourceData == nil {
-		m.errmsg = "param sourceData is nil"
+		m.addError(m.path, reflect.Invalid, nil, errors.New("param sourceData is nil"))
 		return
 	}
 
 	//类型断言
-	str, ok := sourceData.(string)
-	if ok {
-		//if reflect.TypeOf(sourceMap).Kind() == reflect.String
+	var ok bool
+	switch src := sourceData.(type) {
+	case string:
 		//json解码
-		err := json.Unmarshal([]byte(str), &m.sourceMapData)
+		err := m.unmarshal([]byte(src), &sourceData)
 		if err != nil {
-			m.errmsg = err.Error()
+			m.addError(m.path, reflect.String, nil, err)
+			return
+		}
+	case []byte:
+		//json解码
+		err := m.unmarshal(src, &sourceData)
+		if err != nil {
+			m.addError(m.path, reflect.Slice, nil, err)
+			return
+		}
+	}
+
+	//debug 调试信息
+	m.debug("递归调用", "path", m.path, "target", fmt.Sprintf("%T", destStructData))
+
+	//调试时打印map的值
+	//for i,v := range mapData.(map[string]interface{}) {
+	//	fmt.Printf("key=%s,val=%v,type=%T\n",i,v,v)
+	//}
+
+	m.structTypeOf = reflect.TypeOf(destStructData)
+	//数据接收参数必须是指针类型
+	if m.structTypeOf.Kind() != reflect.Ptr {
+		m.addError(m.path, kindOf(sourceData), nil, errors.New("param destStructData is not ptr"))
+		return
+	}
+	m.structTofElem = m.structTypeOf.Elem()
+	m.structValueOf = reflect.ValueOf(destStructData)
+	m.structVofElem = m.structValueOf.Elem()
+
+	//目标是切片、数组、map、指针时直接映射
+	switch m.structTofElem.Kind() {
+	case reflect.Slice, reflect.Array:
+		if _, ok = sourceData.([]interface{}); !ok {
+			m.addError(m.path, kindOf(sourceData), m.structTofElem, errors.New("sourceData type is not []interface{}"))
+			return
+		}
+		if m.structTofElem.Kind() == reflect.Slice {
+			m.setSlice(m.structVofElem, sourceData, m.path)
+		} else {
+			m.setArray(m.structVofElem, sourceData, nil, m.path)
+		}
+		return
+	case reflect.Map:
+		if _, ok = sourceData.(map[string]interface{}); !ok {
+			m.addError(m.path, kindOf(sourceData), m.structTofElem, errors.New("sourceData type is not map[string]interface{}"))
 			return
 		}
-	} else {
-		m.sourceMapData, ok = sourceData.(map[string]interface{})
-		if !ok {
-			m.errmsg = "sourceData type is not map[string]interface{}"
+		m.setMap(m.structVofElem, sourceData, m.path)
+		return
+	case reflect.Ptr:
+		//多级指针逐级创建
+		m.setValue(m.structVofElem, sourceData, nil, m.path)
+		return
+	case reflect.Struct:
+	default:
+		m.addError(m.path, kindOf(sourceData), nil, errors.New("param destStructData is not struct"))
+		return
+	}
+
+	m.sourceMapData, ok = sourceData.(map[string]interface{})
+	if !ok {
+		m.addError(m.path, kindOf(sourceData), nil, errors.New("sourceData type is not map[string]interface{}"))
+		return
+	}
+
+	//循环映射每个结构体字段
+	numField := m.structVofElem.NumField() //结构体字段个数
+	for i := 0; i < numField && !m.aborted(); i++ {
+		m.transformField(i)
+	}
+}
+
+// transformField 映射结构体第i个字段，字段转换中的panic记录为该字段的错误
+func (m *MapToStruct) transformField(i int) {
+	field := m.structTofElem.Field(i)
+	//检测是否能被设置值，标签为"-"的字段忽略
+	if !m.structVofElem.Field(i).CanSet() || m.fieldIgnored(field) {
+		return
+	}
+	//展开的字段从当前层级的map中取值
+	if m.fieldInline(field) {
+		m.transformInline(i)
+		return
+	}
+	_, opts := m.fieldTag(field)
+
+	//获取map对应的value
+	//字段路径使用标签名（多个key时为第一个）或字段名，与源数据实际使用的key无关
+	mapVal, ok2, err := m.getMapValue(i)
+	fieldPath := joinPath(m.path, m.fieldKey(field))
+	if err != nil {
+		//源数据的key有歧义，无论ErrorPolicy如何都记录错误，也不使用默认值
+		m.addError(fieldPath, reflect.Invalid, field.Type, err)
+		return
+	}
+	if !ok2 {
+		switch {
+		case opts.Has("required"):
+			m.addError(fieldPath, reflect.Invalid, field.Type, ErrRequired)
+			return
+		case opts.Has("default"):
+			//使用默认值，默认值按字符串转换成字段类型
+			mapVal = opts.Get("default")
+		default:
+			m.debug("获取结构体字段对应map的值失败", "path", fieldPath, "field", field.Name)
+			return
+		}
+	} else {
+		m.presence[fieldPath] = mapVal == nil
+	}
+
+	//捕获异常
+	defer m.recoverPanic(fieldPath, sourceKind(mapVal), field.Type)
+
+	//string选项，字符串字段的值是json编码的字符串，如 "\"admin\""
+	if str, ok := mapVal.(string); ok && opts.Has("string") && derefType(field.Type).Kind() == reflect.String {
+		if unquoted, err := strconv.Unquote(str); err == nil {
+			mapVal = unquoted
+		}
+	}
+	m.setValue(m.structVofElem.Field(i), mapVal, opts, fieldPath)
+}
+
+// transformInline 把展开的结构体字段映射到当前层级的map，指针为nil时创建
+func (m *MapToStruct) transformInline(i int) {
+	dst := m.structVofElem.Field(i)
+	//捕获异常
+	defer m.recoverPanic(m.path, reflect.Map, dst.Type())
+
+	if dst.Kind() == reflect.Ptr {
+		if dst.IsNil() {
+			dst.Set(reflect.New(dst.Type().Elem()))
+		}
+		dst = dst.Elem()
+	}
+	m.cloneMapToStruct(m.path).transform(dst.Addr().Interface(), m.sourceMapData)
+}
+
+// setValue 把mapVal转换成dst的类型写入dst，dst可以是结构体字段、切片、数组、map的元素，opts为字段标签选项
+func (m *MapToStruct) setValue(dst reflect.Value, mapVal interface{}, opts tagOptions, fieldPath string) {
+	//Optional[T] 记录字段是否出现
+	if dst.CanAddr() {
+		if opt, ok := dst.Addr().Interface().(optionalField); ok {
+			m.setOptional(opt, mapVal, opts, fieldPath)
 			return
 		}
 	}
 
-	//debug 调试信息
-	if m.Debug {
-		log.Println("递归调用，", "目标:", destStructData, "数据源:", m.sourceMapData)
+	//源数据为null
+	if mapVal == nil {
+		m.setNull(dst, opts, fieldPath)
+		return
 	}
 
-	//调试时打印map的值
-	//for i,v := range mapData.(map[string]interface{}) {
-	//	fmt.Printf("key=%s,val=%v,type=%T\n",i,v,v)
-	//}
+	//源数据的原类型，转换后的值类型可能不同，如整数目标的json.Number按字符串解析，错误信息使用原类型
+	srcKind := sourceKind(mapVal)
 
-	m.structTypeOf = reflect.TypeOf(destStructData)
-	//数据接收参数必须是指针类型
-	if m.structTypeOf.Kind() != reflect.Ptr {
-		m.errmsg = "param destStructData is not ptr"
+	//转换函数按源数据的原类型查找，Go数据源中的值原样传给转换函数
+	if fn := m.converter(srcKind, dst.Type()); fn != nil {
+		m.setConverted(dst, mapVal, fn, fieldPath)
 		return
 	}
-	m.structTofElem = m.structTypeOf.Elem()
-	m.structValueOf = reflect.ValueOf(destStructData)
-	m.structVofElem = m.structValueOf.Elem()
 
-	//循环映射每个结构体字段
-	numField := m.structVofElem.NumField() //结构体字段个数
-	for i := 0; i < numField; i++ {
-		//检测是否能被设置值
-		if !m.structVofElem.Field(i).CanSet() {
-			continue
-		}
+	//Go数据源中的值按目标类型转换成json解码后的类型
+	mapVal = sourceValue(mapVal, dst.Type())
 
-		//获取map对应的value
-		mapVal, ok2, tagName := m.getMapValue(i)
-		if !ok2 {
-			if m.Debug {
-				log.Println("结构体第%d个字段(%s)，获取对应map的值失败", i, m.structTofElem.Field(i).Name)
-			}
-			continue
-		}
+	//时间类型以及实现了解码接口的类型
+	if handled, _ := m.setSpecial(dst, mapVal, srcKind, opts, fieldPath); handled {
+		return
+	}
+
+	//json.Number按目标类型解析，指针在创建指向的对象后按指向的类型解析
+	if num, ok := mapVal.(json.Number); ok && dst.Kind() != reflect.Ptr {
+		mapVal = numberValue(num, dst.Type())
+	}
 
-		//结构体字段类型
-		structFieldType := m.structTofElem.Field(i).Type.Kind()
+	//结构体字段类型
+	structFieldType := dst.Kind()
 
-		//map对应值的类型
-		mapValueType := reflect.TypeOf(mapVal).Kind()
-		if m.Debug {
-			log.Println("map(", mapValueType, ") -> ", "struct(", structFieldType, ")")
+	//map对应值的类型
+	mapValueType := reflect.TypeOf(mapVal).Kind()
+	m.debug("转换字段", logAttrs(fieldPath, srcKind, structFieldType)...)
+	//类型相同的直接set
+	if structFieldType == mapValueType {
+		switch structFieldType {
+		case reflect.Slice: //如果都是切片
+			m.setSlice(dst, mapVal, fieldPath)
+		case reflect.Map: //如果都是map
+			m.setMap(dst, mapVal, fieldPath)
+		default:
+			//其他基本类型直接set，字段可以是基础类型定义的类型，如 type Status string
+			dst.Set(reflect.ValueOf(mapVal).Convert(dst.Type()))
 		}
-		//类型相同的直接set
-		if structFieldType == mapValueType {
-			switch structFieldType {
-			case reflect.Slice: //如果都是切片
-				m.setSlice(i, mapVal)
-			case reflect.Map: //如果都是map
-				m.setMap(i, mapVal)
-			default:
-				//其他基本类型直接set
-				m.structVofElem.Field(i).Set(reflect.ValueOf(mapVal))
-			}
-		} else {
-			//结构体字段类型和map key对应值的类型不一致
-			switch structFieldType {
-			//结构体值类型为int 一类
-			case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
-				m.transformInt(i, &mapVal, &tagName, mapValueType)
-			//结构体值类型为uint 一类
-			case reflect.Uint, reflect.Uintptr, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
-				m.transformUint(i, &mapVal, &tagName, mapValueType)
-			//结构体值类型为 float 一类
-			case reflect.Float32, reflect.Float64:
-				m.transformFloat(i, &mapVal, &tagName, mapValueType)
-			//结构体值类型为 bool
-			case reflect.Bool:
-				m.transformBool(i, &mapVal, mapValueType)
-			//结构体值类型为 string
-			case reflect.String:
-				m.transformString(i, &mapVal, mapValueType)
-			//结构体值类型为 struct
-			case reflect.Struct:
-				m.transformStruct(i, mapVal, mapValueType)
-			//结构体值类型为 struct
-			case reflect.Slice:
-				m.transformSlice(i, mapVal, mapValueType)
-			//结构体值类型为 Map
-			case reflect.Map:
-				m.transformMap(i, mapVal, mapValueType)
-			//引用类型
-			case reflect.Ptr:
-				m.transformPtr(i, mapVal, mapValueType)
-			default:
+	} else {
+		//结构体字段类型和map key对应值的类型不一致
+		switch structFieldType {
+		//结构体值类型为int 一类
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			m.transformInt(dst, &mapVal, &fieldPath, mapValueType, srcKind)
+		//结构体值类型为uint 一类
+		case reflect.Uint, reflect.Uintptr, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			m.transformUint(dst, &mapVal, &fieldPath, mapValueType, srcKind)
+		//结构体值类型为 float 一类
+		case reflect.Float32, reflect.Float64:
+			m.transformFloat(dst, &mapVal, &fieldPath, mapValueType, srcKind)
+		//结构体值类型为 bool
+		case reflect.Bool:
+			m.transformBool(dst, &mapVal, &fieldPath, mapValueType, srcKind)
+		//结构体值类型为 string
+		case reflect.String:
+			m.transformString(dst, &mapVal, &fieldPath, mapValueType, srcKind)
+		//结构体值类型为 struct
+		case reflect.Struct:
+			m.transformStruct(dst, mapVal, mapValueType, fieldPath)
+		//结构体值类型为 struct
+		case reflect.Slice:
+			m.transformSlice(dst, mapVal, mapValueType, fieldPath)
+		//结构体值类型为数组
+		case reflect.Array:
+			m.transformArray(dst, mapVal, mapValueType, opts, fieldPath)
+		//结构体值类型为 Map
+		case reflect.Map:
+			m.transformMap(dst, mapVal, mapValueType, fieldPath)
+		//引用类型
+		case reflect.Ptr:
+			m.transformPtr(dst, mapVal, mapValueType, opts, fieldPath)
+		//结构体值类型为 interface
+		case reflect.Interface:
+			if reflect.TypeOf(mapVal).AssignableTo(dst.Type()) {
+				dst.Set(reflect.ValueOf(mapVal))
+			} else {
+				m.coerceFail(fieldPath, srcKind, dst.Type(), ErrUnsupportedConversion)
 			}
+		default:
 		}
 	}
-	m.Success = true
 }
 
-func (m *MapToStruct) transformInt(i int, mapVal *interface{}, mapKey *string, mapValueType reflect.Kind) {
+func (m *MapToStruct) transformInt(dst reflect.Value, mapVal *interface{}, fieldPath *string, mapValueType reflect.Kind, srcKind reflect.Kind) {
 	switch mapValueType {
 	case reflect.Float64:
-		m.structVofElem.Field(i).SetInt(int64((*mapVal).(float64)))
+		f64 := (*mapVal).(float64)
+		if err := checkInteger(f64, f64 < math.MinInt64 || f64 >= math.MaxInt64 || dst.OverflowInt(int64(f64)), dst.Type()); err == nil {
+			dst.SetInt(int64(f64))
+		} else {
+			m.coerceFail(*fieldPath, srcKind, dst.Type(), err)
+		}
 	case reflect.String:
 		str := strings.Trim((*mapVal).(string), "\t\n\r ")
 		if len(str) > 0 {
-			i64, err := strconv.ParseInt(str, 10, 64)
+			//按字段的位数解析，超出范围时返回错误
+			i64, err := strconv.ParseInt(str, 10, dst.Type().Bits())
 			if err == nil {
-				m.structVofElem.Field(i).SetInt(i64)
+				dst.SetInt(i64)
 			} else {
-				log.Println("字段%q%q转换成%v失败：%s,忽略转换", *mapKey, *mapVal, "int族", err.Error())
+				m.coerceFail(*fieldPath, srcKind, dst.Type(), err)
 			}
 		} else {
-			log.Println("字段%q为空,忽略转换成%v,", *mapKey, "int族")
+			m.skipEmpty(*fieldPath, srcKind, dst.Type().Kind())
 		}
 	default:
+		m.coerceFail(*fieldPath, srcKind, dst.Type(), ErrUnsupportedConversion)
+	}
+}
+
+// checkInteger 检查float64能否无损转换成整数字段，overflow为是否超出字段的范围
+func checkInteger(f64 float64, overflow bool, targetType reflect.Type) error {
+	if f64 != math.Trunc(f64) {
+		return fmt.Errorf("value %v is not an integer", f64)
 	}
+	if overflow {
+		return fmt.Errorf("value %v overflows %s", f64, targetType)
+	}
+	return nil
 }
 
-func (m *MapToStruct) transformUint(i int, mapVal *interface{}, mapKey *string, mapValueType reflect.Kind) {
+func (m *MapToStruct) transformUint(dst reflect.Value, mapVal *interface{}, fieldPath *string, mapValueType reflect.Kind, srcKind reflect.Kind) {
 	switch mapValueType {
 	case reflect.Float64:
-		m.structVofElem.Field(i).SetUint(uint64((*mapVal).(float64)))
+		f64 := (*mapVal).(float64)
+		if err := checkInteger(f64, f64 < 0 || f64 >= math.MaxUint64 || dst.OverflowUint(uint64(f64)), dst.Type()); err == nil {
+			dst.SetUint(uint64(f64))
+		} else {
+			m.coerceFail(*fieldPath, srcKind, dst.Type(), err)
+		}
 	case reflect.String:
 		str := strings.Trim((*mapVal).(string), "\t\n\r ")
 		if len(str) > 0 {
-			i64, err := strconv.ParseUint(str, 10, 64)
+			//按字段的位数解析，超出范围时返回错误
+			i64, err := strconv.ParseUint(str, 10, dst.Type().Bits())
 			if err == nil {
-				m.structVofElem.Field(i).SetUint(i64)
+				dst.SetUint(i64)
 			} else {
-				log.Println("字段%q%q转换成%v失败：%s,忽略转换", *mapKey, *mapVal, "uint族", err.Error())
+				m.coerceFail(*fieldPath, srcKind, dst.Type(), err)
 			}
 		} else {
-			log.Println("字段%q为空,忽略转换成%v,", *mapKey, "uint族")
+			m.skipEmpty(*fieldPath, srcKind, dst.Type().Kind())
 		}
 	default:
+		m.coerceFail(*fieldPath, srcKind, dst.Type(), ErrUnsupportedConversion)
 	}
 }
 
-func (m *MapToStruct) transformFloat(i int, mapVal *interface{}, mapKey *string, mapValueType reflect.Kind) {
+func (m *MapToStruct) transformFloat(dst reflect.Value, mapVal *interface{}, fieldPath *string, mapValueType reflect.Kind, srcKind reflect.Kind) {
 	switch mapValueType {
 	case reflect.Float64:
-		m.structVofElem.Field(i).SetFloat((*mapVal).(float64))
+		if f64 := (*mapVal).(float64); !dst.OverflowFloat(f64) {
+			dst.SetFloat(f64)
+		} else {
+			m.coerceFail(*fieldPath, srcKind, dst.Type(), fmt.Errorf("value %v overflows %s", f64, dst.Type()))
+		}
 	case reflect.String:
 		str := strings.Trim((*mapVal).(string), "\t\n\r ")
 		if len(str) > 0 {
-			f64, err := strconv.ParseFloat(str, 64)
+			f64, err := strconv.ParseFloat(str, dst.Type().Bits())
 			if err == nil {
-				m.structVofElem.Field(i).SetFloat(f64)
+				dst.SetFloat(f64)
 			} else {
-				log.Println("字段%q%q转换成%v失败：%s,忽略转换", *mapKey, *mapVal, "float族", err.Error())
+				m.coerceFail(*fieldPath, srcKind, dst.Type(), err)
 			}
 		} else {
-			log.Println("字段%q为空,忽略转换成%v,", *mapKey, "float族")
+			m.skipEmpty(*fieldPath, srcKind, dst.Type().Kind())
 		}
 	default:
+		m.coerceFail(*fieldPath, srcKind, dst.Type(), ErrUnsupportedConversion)
 	}
 }
 
-func (m *MapToStruct) transformBool(i int, mapVal *interface{}, mapValueType reflect.Kind) {
+func (m *MapToStruct) transformBool(dst reflect.Value, mapVal *interface{}, fieldPath *string, mapValueType reflect.Kind, srcKind reflect.Kind) {
 	switch mapValueType {
 	case reflect.String:
 		mapValStr := strings.ToLower((*mapVal).(string))
 		if mapValStr == "true" || mapValStr == "1" {
-			m.structVofElem.Field(i).SetBool(true)
+			dst.SetBool(true)
 		} else if mapValStr == "false" || mapValStr == "0" {
-			m.structVofElem.Field(i).SetBool(false)
+			dst.SetBool(false)
+		} else {
+			m.coerceFail(*fieldPath, srcKind, dst.Type(), fmt.Errorf("invalid bool value %q", mapValStr))
 		}
 	case reflect.Float64:
-		mapValInt := int8((*mapVal).(float64))
-		if mapValInt == 1 {
-			m.structVofElem.Field(i).SetBool(true)
-		} else if mapValInt == 0 {
-			m.structVofElem.Field(i).SetBool(false)
+		//只接受0和1，其他数字不截断
+		mapValNum := (*mapVal).(float64)
+		if mapValNum == 1 {
+			dst.SetBool(true)
+		} else if mapValNum == 0 {
+			dst.SetBool(false)
+		} else {
+			m.coerceFail(*fieldPath, srcKind, dst.Type(), fmt.Errorf("invalid bool value %v", *mapVal))
 		}
 	default:
+		m.coerceFail(*fieldPath, srcKind, dst.Type(), ErrUnsupportedConversion)
 	}
 }
 
-func (m *MapToStruct) transformString(i int, mapVal *interface{}, mapValueType reflect.Kind) {
+func (m *MapToStruct) transformString(dst reflect.Value, mapVal *interface{}, fieldPath *string, mapValueType reflect.Kind, srcKind reflect.Kind) {
 	switch mapValueType {
 	case reflect.Float64:
-		mapValStr := fmt.Sprintf("%.f", (*mapVal).(float64))
-		m.structVofElem.Field(i).SetString(mapValStr)
+		//保留小数，如3.75转换成"3.75"
+		mapValStr := strconv.FormatFloat((*mapVal).(float64), 'f', -1, 64)
+		dst.SetString(mapValStr)
 	default:
+		m.coerceFail(*fieldPath, srcKind, dst.Type(), ErrUnsupportedConversion)
 	}
 }
 
-func (m *MapToStruct) transformStruct(i int, mapVal interface{}, mapValueType reflect.Kind) {
-	if mapValueType == reflect.Map {
-		m.cloneMapToStruct().Transform(m.structVofElem.Field(i).Addr().Interface(), mapVal)
+var (
+	timeType     = reflect.TypeOf(time.Time{})
+	durationType = reflect.TypeOf(time.Duration(0))
+)
+
+// setOptional 把mapVal映射到Optional[T]的值，转换失败或为空字符串时Present仍为true，Value保持零值
+func (m *MapToStruct) setOptional(dst optionalField, mapVal interface{}, opts tagOptions, fieldPath string) {
+	//与Presence一致，源数据中有该字段即为出现，与转换是否成功无关
+	dst.setPresent(mapVal == nil)
+	if mapVal != nil {
+		m.setValue(dst.optionalValue(), mapVal, opts, fieldPath)
 	}
 }
 
-func (m *MapToStruct) transformSlice(i int, mapVal interface{}, mapValueType reflect.Kind) {
-	if mapValueType == reflect.Map {
-		//创建结构体map里面元素的结构体对象
-		valTmp := reflect.Indirect(m.structVofElem.Field(i))
-		structVal := reflect.New(valTmp.Type().Elem())
+// setNull 源数据为null，指针、切片、map、interface设置为nil，其他类型按NullPolicy或标签选项null处理
+func (m *MapToStruct) setNull(dst reflect.Value, opts tagOptions, fieldPath string) {
+	m.debug("源数据为null", logAttrs(fieldPath, reflect.Invalid, dst.Kind())...)
+	switch dst.Kind() {
+	case reflect.Ptr, reflect.Slice, reflect.Map, reflect.Interface:
+		dst.Set(reflect.Zero(dst.Type()))
+		return
+	}
 
-		//循环目标map处理
-		for _, v := range mapVal.(map[string]interface{}) {
-			//递归处理
-			m.cloneMapToStruct().Transform(structVal.Interface(), v)
+	policy, err := m.nullPolicy(opts)
+	if err != nil {
+		m.coerceFail(fieldPath, reflect.Invalid, dst.Type(), err)
+		return
+	}
+	switch policy {
+	case NullPolicyZero:
+		dst.Set(reflect.Zero(dst.Type()))
+	case NullPolicyStrict:
+		m.addError(fieldPath, reflect.Invalid, dst.Type(), ErrNull)
+	}
+}
+
+// nullPolicy 获取源数据为null时的处理策略，标签选项null优先于NullPolicy
+func (m *MapToStruct) nullPolicy(opts tagOptions) (NullPolicy, error) {
+	if !opts.Has("null") {
+		return m.NullPolicy, nil
+	}
+	policy, ok := nullPolicies[opts.Get("null")]
+	if !ok {
+		return 0, fmt.Errorf("unknown null policy %q", opts.Get("null"))
+	}
+	return policy, nil
+}
+
+// setSpecial 目标类型是时间类型，或实现了json.Unmarshaler、encoding.TextUnmarshaler时转换，
+// handled表示已处理，ok表示转换成功，srcKind为记录错误时源数据的类型。字符串源数据优先使用UnmarshalText，其他源数据优先重新编码成json后使用UnmarshalJSON
+func (m *MapToStruct) setSpecial(dst reflect.Value, mapVal interface{}, srcKind reflect.Kind, opts tagOptions, fieldPath string) (handled bool, ok bool) {
+	if isTimeType(dst.Type()) {
+		return true, m.setTime(dst, mapVal, srcKind, opts, fieldPath)
+	}
 
-			//把节点append进上层结构体
-			m.structVofElem.Field(i).Set(reflect.Append(m.structVofElem.Field(i), structVal.Elem()))
+	ptr := dst.Addr().Interface()
+	tu, isText := ptr.(encoding.TextUnmarshaler)
+	ju, isJSON := ptr.(json.Unmarshaler)
+	if !isText && !isJSON {
+		return false, false
+	}
+
+	var err error
+	str, isStr := mapVal.(string)
+	switch {
+	case isStr && isText:
+		err = tu.UnmarshalText([]byte(str))
+	case isJSON:
+		var data []byte
+		if data, err = json.Marshal(mapVal); err == nil {
+			err = ju.UnmarshalJSON(data)
+		}
+	default:
+		if text, isScalar := textValue(mapVal); isScalar {
+			err = tu.UnmarshalText([]byte(text))
+		} else {
+			err = ErrUnsupportedConversion
 		}
 	}
+	if err != nil {
+		m.coerceFail(fieldPath, srcKind, dst.Type(), err)
+		return true, false
+	}
+	return true, true
 }
 
-func (m *MapToStruct) transformMap(i int, mapVal interface{}, mapValueType reflect.Kind) {
-	//需要把map 对应的slice放进strut的map结构中
-	if mapValueType == reflect.Slice {
-		//对应切片结构体的值
-		valTmp := reflect.Indirect(m.structVofElem.Field(i))
-		//切面map的list集合
-		mapValSli := mapVal.([]interface{})
-		//需要make上层map
-		m.structVofElem.Field(i).Set(reflect.MakeMap(m.structVofElem.Field(i).Type()))
+// textValue 把数字、布尔类型的源数据转换成文本
+func textValue(v interface{}) (string, bool) {
+	switch val := v.(type) {
+	case string:
+		return val, true
+	case json.Number:
+		return val.String(), true
+	case float64:
+		return strconv.FormatFloat(val, 'f', -1, 64), true
+	case bool:
+		return strconv.FormatBool(val), true
+	default:
+		return "", false
+	}
+}
 
-		for k, v := range mapValSli {
-			//new一个切片结构体里面的元素
-			structVal := reflect.New(valTmp.Type().Elem())
+var (
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+	jsonUnmarshalerType = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
+)
 
-			//把map映射进结构体
-			m.cloneMapToStruct().Transform(structVal.Interface(), v)
+// isSpecialType 类型(指针则取指向的类型)是否需要setSpecial处理
+func (m *MapToStruct) isSpecialType(t reflect.Type) bool {
+	t = derefType(t)
+	if m.hasConverter(t) || isTimeType(t) {
+		return true
+	}
+	pt := reflect.PointerTo(t)
+	return pt.Implements(textUnmarshalerType) || pt.Implements(jsonUnmarshalerType)
+}
 
-			//把map塞进目标map
-			key := strconv.Itoa(k)
-			m.structVofElem.Field(i).SetMapIndex(reflect.ValueOf(key), structVal.Elem())
-		}
+// isTimeType 是否是time.Time或time.Duration
+func isTimeType(t reflect.Type) bool {
+	return t == timeType || t == durationType
+}
+
+// timeUnit 标签选项unit对应的时间单位，默认值为def
+func timeUnit(opts tagOptions, def time.Duration) (time.Duration, error) {
+	switch opts.Get("unit") {
+	case "":
+		return def, nil
+	case "s":
+		return time.Second, nil
+	case "ms":
+		return time.Millisecond, nil
+	case "us":
+		return time.Microsecond, nil
+	case "ns":
+		return time.Nanosecond, nil
+	default:
+		return 0, fmt.Errorf("invalid time unit %q", opts.Get("unit"))
 	}
 }
 
-func (m *MapToStruct) transformPtr(i int, mapVal interface{}, mapValueType reflect.Kind) {
-	//真实的类型
-	structFieldTypeReal := m.structTofElem.Field(i).Type.Elem().Kind()
-	//log.Println("真实类型：",structFieldTypeReal)
-	switch structFieldTypeReal {
-	case reflect.String:
-		{
-			switch mapValueType {
-			case reflect.String:
-				n := reflect.New(m.structTofElem.Field(i).Type.Elem())
-				n.Elem().Set(reflect.ValueOf(mapVal))
-				m.structVofElem.Field(i).Set(n)
-			case reflect.Float64:
-				mapValStr := fmt.Sprintf("%.f", mapVal.(float64))
-				n := reflect.New(m.structTofElem.Field(i).Type.Elem())
-				n.Elem().Set(reflect.ValueOf(mapValStr))
-				m.structVofElem.Field(i).Set(n)
-			default:
-			}
+// setTime 把源数据转换成time.Time或time.Duration写入dst，转换成功返回true。
+// time.Time：字符串按标签选项layout(默认TimeLayout，再默认RFC3339)解析，数字按unit(默认秒)作为Unix时间戳，
+// 时区使用标签选项tz，默认TimeLocation，再默认UTC，Go数据源中的time.Time只在设置了tz或TimeLocation时转换时区；
+// time.Duration：字符串按time.ParseDuration解析，数字按unit(默认纳秒)换算
+func (m *MapToStruct) setTime(dst reflect.Value, mapVal interface{}, mapValueType reflect.Kind, opts tagOptions, fieldPath string) bool {
+	var num string //数字原文
+	switch val := mapVal.(type) {
+	case time.Time:
+		//Go数据源中的时间直接使用，设置了标签选项tz或TimeLocation时转换时区
+		if dst.Type() != timeType {
+			m.coerceFail(fieldPath, mapValueType, dst.Type(), ErrUnsupportedConversion)
+			return false
 		}
-	case reflect.Struct:
-		{
-			//初始化struct
-			m.structVofElem.Field(i).Set(reflect.New(m.structTofElem.Field(i).Type.Elem()))
-			m.cloneMapToStruct().Transform(m.structVofElem.Field(i).Interface(), mapVal)
-		}
-	case reflect.Int:
-		{
-			switch mapValueType {
-			case reflect.Float64:
-				n := reflect.New(m.structTofElem.Field(i).Type.Elem())
-				n.Elem().Set(reflect.ValueOf(int(mapVal.(float64))))
-				m.structVofElem.Field(i).Set(n)
-			case reflect.String:
-				i64, err := strconv.ParseInt(mapVal.(string), 10, 64)
-				if err == nil {
-					n := reflect.New(m.structTofElem.Field(i).Type.Elem())
-					n.Elem().Set(reflect.ValueOf(int(i64)))
-					m.structVofElem.Field(i).Set(n)
-				} else {
-					log.Println("%q转换成%v失败：%s", mapVal, "int族", err.Error())
-				}
-			default:
+		if opts.Get("tz") != "" || m.TimeLocation != nil {
+			loc, err := m.timeLocation(opts)
+			if err != nil {
+				m.coerceFail(fieldPath, mapValueType, dst.Type(), err)
+				return false
 			}
+			val = val.In(loc)
 		}
-	case reflect.Int8:
-		{
-			switch mapValueType {
-			case reflect.Float64:
-				n := reflect.New(m.structTofElem.Field(i).Type.Elem())
-				n.Elem().Set(reflect.ValueOf(int8(mapVal.(float64))))
-				m.structVofElem.Field(i).Set(n)
-			case reflect.String:
-				i64, err := strconv.ParseInt(mapVal.(string), 10, 64)
-				if err == nil {
-					n := reflect.New(m.structTofElem.Field(i).Type.Elem())
-					n.Elem().Set(reflect.ValueOf(int8(i64)))
-					m.structVofElem.Field(i).Set(n)
-				} else {
-					log.Println("%q转换成%v失败：%s", mapVal, "int族", err.Error())
-				}
-			default:
-			}
+		dst.Set(reflect.ValueOf(val))
+		return true
+	case float64:
+		num = strconv.FormatFloat(val, 'f', -1, 64)
+	case json.Number:
+		num = val.String()
+	case string:
+		str := strings.Trim(val, "\t\n\r ")
+		if len(str) == 0 {
+			m.skipEmpty(fieldPath, mapValueType, dst.Kind())
+			return false
 		}
-	case reflect.Int16:
-		{
-			switch mapValueType {
-			case reflect.Float64:
-				n := reflect.New(m.structTofElem.Field(i).Type.Elem())
-				n.Elem().Set(reflect.ValueOf(int16(mapVal.(float64))))
-				m.structVofElem.Field(i).Set(n)
-			case reflect.String:
-				i64, err := strconv.ParseInt(mapVal.(string), 10, 64)
-				if err == nil {
-					n := reflect.New(m.structTofElem.Field(i).Type.Elem())
-					n.Elem().Set(reflect.ValueOf(int16(i64)))
-					m.structVofElem.Field(i).Set(n)
-				} else {
-					log.Println("%q转换成%v失败：%s", mapVal, "int族", err.Error())
-				}
-			default:
+		var err error
+		if dst.Type() == durationType {
+			var d time.Duration
+			if d, err = time.ParseDuration(str); err == nil {
+				dst.SetInt(int64(d))
+				return true
 			}
-		}
-	case reflect.Int32:
-		{
-			switch mapValueType {
-			case reflect.Float64:
-				n := reflect.New(m.structTofElem.Field(i).Type.Elem())
-				n.Elem().Set(reflect.ValueOf(int32(mapVal.(float64))))
-				m.structVofElem.Field(i).Set(n)
-			case reflect.String:
-				i64, err := strconv.ParseInt(mapVal.(string), 10, 64)
-				if err == nil {
-					n := reflect.New(m.structTofElem.Field(i).Type.Elem())
-					n.Elem().Set(reflect.ValueOf(int32(i64)))
-					m.structVofElem.Field(i).Set(n)
-				} else {
-					log.Println("%q转换成%v失败：%s", mapVal, "int族", err.Error())
-				}
-			default:
+		} else {
+			var t time.Time
+			if t, err = m.parseTime(str, opts); err == nil {
+				dst.Set(reflect.ValueOf(t))
+				return true
 			}
 		}
-	case reflect.Int64:
-		{
-			switch mapValueType {
-			case reflect.Float64:
-				n := reflect.New(m.structTofElem.Field(i).Type.Elem())
-				n.Elem().Set(reflect.ValueOf(int64(mapVal.(float64))))
-				m.structVofElem.Field(i).Set(n)
-			case reflect.String:
-				i64, err := strconv.ParseInt(mapVal.(string), 10, 64)
-				if err == nil {
-					n := reflect.New(m.structTofElem.Field(i).Type.Elem())
-					n.Elem().Set(reflect.ValueOf(i64))
-					m.structVofElem.Field(i).Set(n)
-				} else {
-					log.Println("%q转换成%v失败：%s", mapVal, "int族", err.Error())
-				}
-			default:
-			}
+		//数字字符串按数字处理
+		if _, err2 := strconv.ParseFloat(str, 64); err2 != nil {
+			m.coerceFail(fieldPath, mapValueType, dst.Type(), err)
+			return false
 		}
-	case reflect.Uint:
-		{
-			switch mapValueType {
-			case reflect.Float64:
-				n := reflect.New(m.structVofElem.Field(i).Type().Elem())
-				n.Elem().Set(reflect.ValueOf(uint(mapVal.(float64))))
-				m.structVofElem.Field(i).Set(n)
-			case reflect.String:
-				ui64, err := strconv.ParseUint(mapVal.(string), 10, 64)
-				if err == nil {
-					n := reflect.New(m.structVofElem.Field(i).Type().Elem())
-					n.Elem().Set(reflect.ValueOf(uint(ui64)))
-					m.structVofElem.Field(i).Set(n)
-				} else {
-					log.Println("%q转换成%v失败：%s", mapVal, "uint族", err.Error())
-				}
-			default:
-			}
+		num = str
+	default:
+		m.coerceFail(fieldPath, mapValueType, dst.Type(), ErrUnsupportedConversion)
+		return false
+	}
+
+	//数字按时间单位换算
+	def := time.Second
+	if dst.Type() == durationType {
+		def = time.Nanosecond
+	}
+	unit, err := timeUnit(opts, def)
+	if err != nil {
+		m.coerceFail(fieldPath, mapValueType, dst.Type(), err)
+		return false
+	}
+	if dst.Type() == durationType {
+		d, err := numDuration(num, unit)
+		if err != nil {
+			m.coerceFail(fieldPath, mapValueType, dst.Type(), err)
+			return false
 		}
-	case reflect.Uint8:
-		{
-			switch mapValueType {
-			case reflect.Float64:
-				n := reflect.New(m.structVofElem.Field(i).Type().Elem())
-				n.Elem().Set(reflect.ValueOf(uint8(mapVal.(float64))))
-				m.structVofElem.Field(i).Set(n)
-			case reflect.String:
-				ui64, err := strconv.ParseUint(mapVal.(string), 10, 64)
-				if err == nil {
-					n := reflect.New(m.structVofElem.Field(i).Type().Elem())
-					n.Elem().Set(reflect.ValueOf(uint8(ui64)))
-					m.structVofElem.Field(i).Set(n)
-				} else {
-					log.Println("%q转换成%v失败：%s", mapVal, "uint族", err.Error())
-				}
-			default:
-			}
+		dst.SetInt(int64(d))
+		return true
+	}
+	loc, err := m.timeLocation(opts)
+	if err != nil {
+		m.coerceFail(fieldPath, mapValueType, dst.Type(), err)
+		return false
+	}
+	t, err := numTime(num, unit)
+	if err != nil {
+		m.coerceFail(fieldPath, mapValueType, dst.Type(), err)
+		return false
+	}
+	dst.Set(reflect.ValueOf(t.In(loc)))
+	return true
+}
+
+// numDuration 把数字原文按时间单位换算成time.Duration，整数按整数计算以免丢失精度，小数按float64计算，超出范围时返回错误
+func numDuration(num string, unit time.Duration) (time.Duration, error) {
+	if i64, err := strconv.ParseInt(num, 10, 64); err == nil {
+		if i64 > math.MaxInt64/int64(unit) || i64 < math.MinInt64/int64(unit) {
+			return 0, fmt.Errorf("value %s overflows %s", num, durationType)
 		}
-	case reflect.Uint16:
-		{
-			switch mapValueType {
-			case reflect.Float64:
-				n := reflect.New(m.structVofElem.Field(i).Type().Elem())
-				n.Elem().Set(reflect.ValueOf(uint16(mapVal.(float64))))
-				m.structVofElem.Field(i).Set(n)
-			case reflect.String:
-				ui64, err := strconv.ParseUint(mapVal.(string), 10, 64)
-				if err == nil {
-					n := reflect.New(m.structVofElem.Field(i).Type().Elem())
-					n.Elem().Set(reflect.ValueOf(uint16(ui64)))
-					m.structVofElem.Field(i).Set(n)
-				} else {
-					log.Println("%q转换成%v失败：%s", mapVal, "uint族", err.Error())
-				}
-			default:
-			}
+		return time.Duration(i64) * unit, nil
+	}
+	f64, err := strconv.ParseFloat(num, 64)
+	if err != nil {
+		return 0, err
+	}
+	if f := f64 * float64(unit); f >= math.MinInt64 && f < math.MaxInt64 {
+		return time.Duration(f), nil
+	}
+	return 0, fmt.Errorf("value %s overflows %s", num, durationType)
+}
+
+// numTime 把数字原文按时间单位作为Unix时间戳转换成time.Time，整数按整数计算以免丢失精度，小数按float64计算
+func numTime(num string, unit time.Duration) (time.Time, error) {
+	if i64, err := strconv.ParseInt(num, 10, 64); err == nil {
+		perSecond := int64(time.Second / unit)
+		return time.Unix(i64/perSecond, i64%perSecond*int64(unit)), nil
+	}
+	d, err := numDuration(num, unit)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return time.Unix(0, int64(d)), nil
+}
+
+// parseTime 按标签选项layout、TimeLayout、RFC3339的顺序解析时间字符串
+func (m *MapToStruct) parseTime(str string, opts tagOptions) (time.Time, error) {
+	loc, err := m.timeLocation(opts)
+	if err != nil {
+		return time.Time{}, err
+	}
+	var firstErr error
+	for _, layout := range []string{opts.Get("layout"), m.TimeLayout, time.RFC3339} {
+		if layout == "" {
+			continue
 		}
-	case reflect.Uint32:
-		{
-			switch mapValueType {
-			case reflect.Float64:
-				n := reflect.New(m.structVofElem.Field(i).Type().Elem())
-				n.Elem().Set(reflect.ValueOf(uint32(mapVal.(float64))))
-				m.structVofElem.Field(i).Set(n)
-			case reflect.String:
-				ui64, err := strconv.ParseUint(mapVal.(string), 10, 64)
-				if err == nil {
-					n := reflect.New(m.structVofElem.Field(i).Type().Elem())
-					n.Elem().Set(reflect.ValueOf(uint32(ui64)))
-					m.structVofElem.Field(i).Set(n)
-				} else {
-					log.Println("%q转换成%v失败：%s", mapVal, "uint族", err.Error())
-				}
-			default:
-			}
+		t, err := time.ParseInLocation(layout, str, loc)
+		if err == nil {
+			return t, nil
 		}
-	case reflect.Uint64:
-		{
-			switch mapValueType {
-			case reflect.Float64:
-				n := reflect.New(m.structVofElem.Field(i).Type().Elem())
-				n.Elem().Set(reflect.ValueOf(uint64(mapVal.(float64))))
-				m.structVofElem.Field(i).Set(n)
-			case reflect.String:
-				ui64, err := strconv.ParseUint(mapVal.(string), 10, 64)
-				if err == nil {
-					n := reflect.New(m.structVofElem.Field(i).Type().Elem())
-					n.Elem().Set(reflect.ValueOf(uint64(ui64)))
-					m.structVofElem.Field(i).Set(n)
-				} else {
-					log.Println("%q转换成%v失败：%s", mapVal, "uint族", err.Error())
-				}
-			default:
-			}
+		if firstErr == nil {
+			firstErr = err
 		}
-	case reflect.Float32:
-		{
-			switch mapValueType {
-			case reflect.Float64:
-				n := reflect.New(m.structTofElem.Field(i).Type.Elem())
-				n.Elem().Set(reflect.ValueOf(float32(mapVal.(float64))))
-				m.structVofElem.Field(i).Set(n)
-			case reflect.String:
-				f64, err := strconv.ParseFloat(mapVal.(string), 64)
-				if err == nil {
-					n := reflect.New(m.structTofElem.Field(i).Type.Elem())
-					n.Elem().Set(reflect.ValueOf(float32(f64)))
-					m.structVofElem.Field(i).Set(n)
-				} else {
-					log.Println("%q转换成%v失败：%s", mapVal, "float族", err.Error())
-				}
-			default:
+	}
+	return time.Time{}, firstErr
+}
+
+// timeLocation 获取时区，优先使用标签选项tz，其次TimeLocation，默认UTC
+func (m *MapToStruct) timeLocation(opts tagOptions) (*time.Location, error) {
+	if tz := opts.Get("tz"); tz != "" {
+		return time.LoadLocation(tz)
+	}
+	if m.TimeLocation != nil {
+		return m.TimeLocation, nil
+	}
+	return time.UTC, nil
+}
+
+func (m *MapToStruct) transformStruct(dst reflect.Value, mapVal interface{}, mapValueType reflect.Kind, fieldPath string) {
+	if mapValueType == reflect.Map {
+		m.cloneMapToStruct(fieldPath).transform(dst.Addr().Interface(), mapVal)
+	} else {
+		m.coerceFail(fieldPath, mapValueType, dst.Type(), ErrUnsupportedConversion)
+	}
+}
+
+func (m *MapToStruct) transformSlice(dst reflect.Value, mapVal interface{}, mapValueType reflect.Kind, fieldPath string) {
+	if mapValueType == reflect.Map {
+		//循环目标map处理
+		for k, v := range mapVal.(map[string]interface{}) {
+			if m.aborted() {
+				break
+			}
+			//递归处理，把节点append进上层结构体
+			if elem, ok := m.newElem(dst.Type().Elem(), v, joinPath(fieldPath, k)); ok {
+				dst.Set(reflect.Append(dst, elem))
 			}
 		}
-	case reflect.Float64:
-		{
-			switch mapValueType {
-			case reflect.Float64:
-				n := reflect.New(m.structTofElem.Field(i).Type.Elem())
-				n.Elem().Set(reflect.ValueOf(mapVal))
-				m.structVofElem.Field(i).Set(n)
+	} else {
+		m.coerceFail(fieldPath, mapValueType, dst.Type(), ErrUnsupportedConversion)
+	}
+}
+
+func (m *MapToStruct) transformArray(dst reflect.Value, mapVal interface{}, mapValueType reflect.Kind, opts tagOptions, fieldPath string) {
+	if mapValueType == reflect.Slice {
+		m.setArray(dst, mapVal, opts, fieldPath)
+	} else {
+		m.coerceFail(fieldPath, mapValueType, dst.Type(), ErrUnsupportedConversion)
+	}
+}
+
+func (m *MapToStruct) transformMap(dst reflect.Value, mapVal interface{}, mapValueType reflect.Kind, fieldPath string) {
+	//需要把map 对应的slice放进strut的map结构中
+	if mapValueType == reflect.Slice {
+		//切面map的list集合
+		mapValSli := mapVal.([]interface{})
+		//需要make上层map
+		dst.Set(reflect.MakeMap(dst.Type()))
+
+		//map的key为切片下标
+		keyType := dst.Type().Key()
+		for k, v := range mapValSli {
+			if m.aborted() {
+				break
+			}
+			key := reflect.New(keyType).Elem()
+			switch keyType.Kind() {
 			case reflect.String:
-				f64, err := strconv.ParseFloat(mapVal.(string), 64)
-				if err == nil {
-					n := reflect.New(m.structTofElem.Field(i).Type.Elem())
-					n.Elem().Set(reflect.ValueOf(f64))
-					m.structVofElem.Field(i).Set(n)
-				} else {
-					log.Println("%q转换成%v失败：%s", mapVal, "float族", err.Error())
-				}
+				key.SetString(strconv.Itoa(k))
+			case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+				key.SetInt(int64(k))
+			case reflect.Uint, reflect.Uintptr, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+				key.SetUint(uint64(k))
 			default:
+				m.coerceFail(fieldPath, mapValueType, dst.Type(), ErrUnsupportedConversion)
+				return
+			}
+
+			//把元素塞进目标map
+			if elem, ok := m.newElem(dst.Type().Elem(), v, indexPath(fieldPath, k)); ok {
+				dst.SetMapIndex(key, elem)
 			}
 		}
-	default:
+	} else {
+		m.coerceFail(fieldPath, mapValueType, dst.Type(), ErrUnsupportedConversion)
 	}
 }
 
-func (m *MapToStruct) setMap(i int, mapVal interface{}) {
-	//创建结构体map里面元素的结构体对象
-	valTmp := reflect.Indirect(m.structVofElem.Field(i))
-	valTmpTpy := valTmp.Type().Elem().Kind()
-
-	var structVal reflect.Value
-	if valTmpTpy == reflect.Ptr {
-		structVal = reflect.New(valTmp.Type().Elem().Elem())
-		//todo 二级指针处理
-		//structVal = reflect.New(valTmp.Type().Elem().Elem().Elem())
-	} else {
-		structVal = reflect.New(valTmp.Type().Elem())
+// transformPtr 创建指针指向的对象，按指向的类型转换mapVal，多级指针逐级创建，转换失败时指针保持不变
+func (m *MapToStruct) transformPtr(dst reflect.Value, mapVal interface{}, mapValueType reflect.Kind, opts tagOptions, fieldPath string) {
+	n := reflect.New(dst.Type().Elem())
+	fails := m.fails
+	m.setValue(n.Elem(), mapVal, opts, fieldPath)
+	if m.converted(fails, fieldPath) {
+		dst.Set(n)
 	}
+}
+
+// setElem 把v映射到切片、数组、map的元素dst(可寻址)，元素可以是基础类型、结构体或嵌套的切片、map
+func (m *MapToStruct) setElem(dst reflect.Value, v interface{}, path string) {
+	//捕获异常
+	defer m.recoverPanic(path, sourceKind(v), dst.Type())
+
+	m.setValue(dst, v, nil, path)
+}
+
+// newElem 创建elemType类型的切片、map元素并把v映射进去，elemType是指针时逐级创建指向的对象，ok为是否转换成功
+func (m *MapToStruct) newElem(elemType reflect.Type, v interface{}, path string) (elem reflect.Value, ok bool) {
+	structVal := reflect.New(elemType)
+	fails := m.fails
+	m.setElem(structVal.Elem(), v, path)
+	return structVal.Elem(), m.converted(fails, path)
+}
 
+// setMap 把map映射到dst(map类型)
+func (m *MapToStruct) setMap(dst reflect.Value, mapVal interface{}, fieldPath string) {
 	//需要make上层map
-	m.structVofElem.Field(i).Set(reflect.MakeMap(m.structVofElem.Field(i).Type()))
-	//上层结构体map key的类型
-	structVofElemKeyType := m.structVofElem.Field(i).Type().Key()
+	dst.Set(reflect.MakeMap(dst.Type()))
+	//上层结构体map key、元素的类型
+	structVofElemKeyType := dst.Type().Key()
+	elemType := dst.Type().Elem()
 
 	//循环目标map处理
-	var mapkey reflect.Value
 	for mk, mv := range mapVal.(map[string]interface{}) {
-		//递归处理
-		m.cloneMapToStruct().Transform(structVal.Interface(), mv)
-
-		//对结构体map key转换处理
-		var i64 int64
-		var ui64 uint64
-		var f64 float64
+		if m.aborted() {
+			break
+		}
+		//对结构体map key转换处理，key可以是基础类型定义的类型，如 type ID int
+		mapkey := reflect.New(structVofElemKeyType).Elem()
 		var err error
 		switch structVofElemKeyType.Kind() {
 		case reflect.String:
-			{
-				mapkey = reflect.ValueOf(mk)
-			}
+			mapkey.SetString(mk)
 		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
-			{
-				i64, err = strconv.ParseInt(mk, 10, 64)
-				if err == nil {
-					switch structVofElemKeyType.Kind() {
-					case reflect.Int:
-						mapkey = reflect.ValueOf(int(i64))
-					case reflect.Int8:
-						mapkey = reflect.ValueOf(int8(i64))
-					case reflect.Int16:
-						mapkey = reflect.ValueOf(int16(i64))
-					case reflect.Int32:
-						mapkey = reflect.ValueOf(int32(i64))
-					case reflect.Int64:
-						mapkey = reflect.ValueOf(i64)
-					default:
-						if m.Debug {
-							log.Println("未识别的数据类型:", structVofElemKeyType.Kind())
-						}
-					}
-				}
+			var i64 int64
+			if i64, err = strconv.ParseInt(mk, 10, structVofElemKeyType.Bits()); err == nil {
+				mapkey.SetInt(i64)
 			}
 		case reflect.Uint, reflect.Uintptr, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
-			{
-				ui64, err = strconv.ParseUint(mk, 10, 64)
-				if err == nil {
-					switch structVofElemKeyType.Kind() {
-					case reflect.Uint:
-						mapkey = reflect.ValueOf(uint(ui64))
-					case reflect.Uintptr:
-						mapkey = reflect.ValueOf(uintptr(ui64))
-					case reflect.Uint8:
-						mapkey = reflect.ValueOf(uint8(ui64))
-					case reflect.Uint16:
-						mapkey = reflect.ValueOf(uint16(ui64))
-					case reflect.Uint32:
-						mapkey = reflect.ValueOf(uint32(ui64))
-					case reflect.Uint64:
-						mapkey = reflect.ValueOf(ui64)
-					default:
-						if m.Debug {
-							log.Println("未识别的数据类型:", structVofElemKeyType.Kind())
-						}
-					}
-				}
+			var ui64 uint64
+			if ui64, err = strconv.ParseUint(mk, 10, structVofElemKeyType.Bits()); err == nil {
+				mapkey.SetUint(ui64)
 			}
 		case reflect.Float32, reflect.Float64:
-			{
-				f64, err = strconv.ParseFloat(mk, 64)
-				if err == nil {
-					switch structVofElemKeyType.Kind() {
-					case reflect.Float32:
-						mapkey = reflect.ValueOf(float32(f64))
-					case reflect.Float64:
-						mapkey = reflect.ValueOf(f64)
-					default:
-						if m.Debug {
-							log.Println("未识别的数据类型:", structVofElemKeyType.Kind())
-						}
-					}
-				}
+			var f64 float64
+			if f64, err = strconv.ParseFloat(mk, structVofElemKeyType.Bits()); err == nil {
+				mapkey.SetFloat(f64)
 			}
 		default:
-			if m.Debug {
-				log.Println("未识别的数据类型:", reflect.String)
-			}
-		}
-		//如果key有效则set值
-		if mapkey.IsValid() {
-			//把map塞进目标map
-			if valTmpTpy == reflect.Ptr {
-				m.structVofElem.Field(i).SetMapIndex(mapkey, structVal)
-
-				//todo 二级指针处理
-				//structVofElem.Field(i).SetMapIndex(mapkey , reflect.New(structVal.Type()))
-			} else {
-				m.structVofElem.Field(i).SetMapIndex(mapkey, structVal.Elem())
-			}
+			m.debug("未识别的map key类型", logAttrs(joinPath(fieldPath, mk), reflect.String, structVofElemKeyType.Kind())...)
+			continue
 		}
-
 		//转换失败
 		if err != nil {
-			if m.Debug {
-				log.Println("map key(string) transform fail:", err.Error())
-			}
+			m.coerceFail(joinPath(fieldPath, mk), reflect.String, structVofElemKeyType, err)
+			continue
+		}
+
+		//递归处理，把元素塞进目标map
+		if elem, ok := m.newElem(elemType, mv, joinPath(fieldPath, mk)); ok {
+			dst.SetMapIndex(mapkey, elem)
 		}
 	}
 }
 
-func (m *MapToStruct) setSlice(i int, mapVal interface{}) {
-	//实现方式一 [开始]
-	//对应切片结构体的值
-	valTmp := reflect.Indirect(m.structVofElem.Field(i))
-	valTmpTpy := valTmp.Type().Elem().Kind()
-
-	//切片map的list集合
+// setSlice 把切片映射到dst(切片类型)，dst原有的元素会被替换
+func (m *MapToStruct) setSlice(dst reflect.Value, mapVal interface{}, fieldPath string) {
+	elemType := dst.Type().Elem()
 	mapValSli := mapVal.([]interface{})
-	var structVal reflect.Value
-	for _, v := range mapValSli {
-		//structVal 实现方式一
-		//structVal = reflect.Indirect(reflect.New(valTmp.Type().Elem())).Addr()
-
-		//structVal 实现方式二
-		if valTmpTpy == reflect.Ptr {
-			structVal = reflect.New(valTmp.Type().Elem().Elem())
-		} else {
-			structVal = reflect.New(valTmp.Type().Elem())
+	//与encoding/json一致，清空原有元素后填充，[]映射成空切片而不是nil
+	if dst.IsNil() {
+		dst.Set(reflect.MakeSlice(dst.Type(), 0, len(mapValSli)))
+	} else {
+		dst.SetLen(0)
+	}
+	for k, v := range mapValSli {
+		if m.aborted() {
+			break
+		}
+		//递归处理，把节点append进上层结构体
+		//转换失败的元素不append
+		if elem, ok := m.newElem(elemType, v, indexPath(fieldPath, k)); ok {
+			dst.Set(reflect.Append(dst, elem))
 		}
+	}
+}
 
-		//把map映射进结构体
-		m.cloneMapToStruct().Transform(structVal.Interface(), v)
+// setArray 把切片映射到dst(数组类型)，长度不一致时按ArrayPolicy或标签选项array处理
+func (m *MapToStruct) setArray(dst reflect.Value, mapVal interface{}, opts tagOptions, fieldPath string) {
+	mapValSli := mapVal.([]interface{})
+	policy, err := m.arrayPolicy(opts)
+	if err != nil {
+		m.coerceFail(fieldPath, reflect.Slice, dst.Type(), err)
+		return
+	}
 
-		//把节点append进上层结构体
-		if valTmpTpy == reflect.Ptr {
-			m.structVofElem.Field(i).Set(reflect.Append(m.structVofElem.Field(i), structVal))
-		} else {
-			m.structVofElem.Field(i).Set(reflect.Append(m.structVofElem.Field(i), structVal.Elem()))
-		}
-	}
-	//实现方式一 [结束]
-
-	////实现方式二[开始]
-	//valTmp := reflect.Indirect(m.structVofElem.Field(i))
-	//valTmpTpy := valTmp.Type().Elem().Kind()
-	////节点map切片
-	//mapValSli := mapVal.([]interface{})
-	//mapValSliLen := len(mapValSli)
-	////在结构体make mapValSliLen 个元素
-	//if valTmpTpy == reflect.Ptr {
-	//	m.structVofElem.Field(i).Set(reflect.MakeSlice(valTmp.Type(),mapValSliLen,mapValSliLen))
-	//	for n:=0 ; n<mapValSliLen ; n++ {
-	//		m.structVofElem.Field(i).Index(n).Set(reflect.New(valTmp.Type().Elem().Elem()))
-	//	}
-	//} else {
-	//	m.structVofElem.Field(i).Set(reflect.MakeSlice(valTmp.Type(),mapValSliLen,mapValSliLen))
-	//}
-	//
-	////循环往每个节点添加map对象里面的值
-	//if valTmpTpy == reflect.Ptr {
-	//	for j,v:=range mapValSli {
-	//		m.cloneMapToStruct().Transform(valTmp.Index(j).Elem().Addr().Interface(), v)
-	//	}
-	//} else {
-	//	for j,v:=range mapValSli {
-	//		m.cloneMapToStruct().Transform(valTmp.Index(j).Addr().Interface(), v)
-	//	}
-	//}
-	////实现方式二[结束]
+	//长度检查
+	switch {
+	case len(mapValSli) > dst.Len() && (policy == ArrayPolicyZeroPad || policy == ArrayPolicyStrict),
+		len(mapValSli) < dst.Len() && (policy == ArrayPolicyTruncate || policy == ArrayPolicyStrict):
+		m.coerceFail(fieldPath, reflect.Slice, dst.Type(), fmt.Errorf("%w: want %d, got %d", ErrArrayLength, dst.Len(), len(mapValSli)))
+		return
+	}
+
+	elemType := dst.Type().Elem()
+	for k := 0; k < dst.Len() && !m.aborted(); k++ {
+		//不足的元素置为零值
+		if k >= len(mapValSli) {
+			dst.Index(k).Set(reflect.Zero(elemType))
+			continue
+		}
+		m.setElem(dst.Index(k), mapValSli[k], indexPath(fieldPath, k))
+	}
+}
+
+// arrayPolicy 获取数组长度不一致时的处理策略，标签选项array优先于ArrayPolicy
+func (m *MapToStruct) arrayPolicy(opts tagOptions) (ArrayPolicy, error) {
+	if !opts.Has("array") {
+		return m.ArrayPolicy, nil
+	}
+	policy, ok := arrayPolicies[opts.Get("array")]
+	if !ok {
+		return 0, fmt.Errorf("unknown array policy %q", opts.Get("array"))
+	}
+	return policy, nil
 }
@@ -0,0 +1,55 @@
+package test10
+
+import (
+	JTStools "github.com/sajanray/GoJsonToStruct"
+	"io"
+	"strings"
+	"testing"
+)
+
+type Student struct {
+	Name string `stm:"name"`
+	Age  int    `stm:"age"`
+}
+
+func TestTransformBytes(t *testing.T) {
+	stu := Student{}
+	m := JTStools.NewMapToStruct()
+	m.Tagkey = "stm"
+	if err := m.Transform(&stu, []byte(`{"name":"admin","age":"20"}`)); err != nil {
+		t.Fatal("[]byte转struct失败，err =", err)
+	}
+	if stu.Name != "admin" || stu.Age != 20 {
+		t.Fatal("[]byte转struct结果不正确，stu =", stu)
+	}
+}
+
+func TestDecoder(t *testing.T) {
+	r := strings.NewReader(`{"name":"a","age":"18"} {"name":"b","age":20}`)
+	dec := JTStools.NewDecoder(r)
+	dec.Tagkey = "stm"
+
+	var stus []Student
+	for {
+		stu := Student{}
+		err := dec.Decode(&stu)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal("Decode失败，err =", err)
+		}
+		stus = append(stus, stu)
+	}
+	if len(stus) != 2 || stus[0].Age != 18 || stus[1].Name != "b" {
+		t.Fatal("Decode结果不正确，stus =", stus)
+	}
+}
+
+func TestDecoderSyntaxError(t *testing.T) {
+	stu := Student{}
+	dec := JTStools.NewDecoder(strings.NewReader(`{"name":`))
+	if err := dec.Decode(&stu); err == nil || dec.Success {
+		t.Fatal("不完整的json应返回错误")
+	}
+}
@@ -0,0 +1,87 @@
+package test11
+
+import (
+	"encoding/json"
+	"errors"
+	JTStools "github.com/sajanray/GoJsonToStruct"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type Tweet struct {
+	ID      int64   `json:"id"`
+	UID     uint64  `json:"uid"`
+	PID     *int64  `json:"pid"`
+	IDStr   string  `json:"id_str"`
+	Score   float64 `json:"score"`
+	Count   int     `json:"count"`
+	Visible bool    `json:"visible"`
+}
+
+const str = `{"id":1234567890123456789,"uid":18446744073709551615,"pid":1234567890123456789,"id_str":1234567890123456789,"score":1.5,"count":2,"visible":1}`
+
+func TestUseNumber(t *testing.T) {
+	tw := Tweet{}
+	m := JTStools.NewMapToStruct()
+	m.UseNumber = true
+	if err := m.Transform(&tw, str); err != nil {
+		t.Fatal("json转struct失败，err =", err)
+	}
+	if tw.ID != 1234567890123456789 || tw.UID != 18446744073709551615 || tw.PID == nil || *tw.PID != 1234567890123456789 {
+		t.Fatal("整数丢失精度，tw =", tw)
+	}
+	if tw.IDStr != "1234567890123456789" || tw.Score != 1.5 || tw.Count != 2 || !tw.Visible {
+		t.Fatal("json转struct结果不正确，tw =", tw)
+	}
+}
+
+func TestDecoderUseNumber(t *testing.T) {
+	tw := Tweet{}
+	dec := JTStools.NewDecoder(strings.NewReader(str))
+	dec.UseNumber = true
+	if err := dec.Decode(&tw); err != nil {
+		t.Fatal("Decode失败，err =", err)
+	}
+	if tw.ID != 1234567890123456789 {
+		t.Fatal("整数丢失精度，tw =", tw)
+	}
+}
+
+type Event struct {
+	ID    interface{}            `json:"id"`
+	Ext   map[string]interface{} `json:"ext"`
+	Items []interface{}          `json:"items"`
+}
+
+func TestUseNumberInterface(t *testing.T) {
+	ev := Event{}
+	m := JTStools.NewMapToStruct()
+	m.UseNumber = true
+	if err := m.Transform(&ev, `{"id":9007199254740993,"ext":{"id":9007199254740993},"items":[9007199254740993,1.5]}`); err != nil {
+		t.Fatal("json转struct失败，err =", err)
+	}
+	id := json.Number("9007199254740993")
+	if ev.ID != id || ev.Ext["id"] != id || ev.Items[0] != id || ev.Items[1] != json.Number("1.5") {
+		t.Fatalf("interface字段应保持json.Number，ev = %#v", ev)
+	}
+}
+
+func TestUseNumberOverflow(t *testing.T) {
+	tw := Tweet{}
+	m := JTStools.NewMapToStruct()
+	m.UseNumber = true
+	m.ErrorPolicy = JTStools.ErrorPolicyCollect
+	err := m.Transform(&tw, `{"id":92233720368547758070,"uid":-1}`)
+
+	//错误信息中的源数据类型是json中的数字，不是按字符串解析后的类型
+	var te *JTStools.TransformError
+	if !errors.As(err, &te) || len(te.Errors) != 2 {
+		t.Fatal("超出范围应返回错误，err =", err)
+	}
+	for _, fe := range te.Errors {
+		if fe.SourceKind != reflect.Float64 || !strings.Contains(fe.Error(), "cannot transform float64 into") {
+			t.Fatal("源数据类型不正确，err =", fe)
+		}
+	}
+}
@@ -0,0 +1,53 @@
+package test12
+
+import (
+	JTStools "github.com/sajanray/GoJsonToStruct"
+	"strings"
+	"testing"
+)
+
+type Event struct {
+	ID   int    `json:"id"`
+	Type string `json:"type"`
+}
+
+func TestTransformLines(t *testing.T) {
+	r := strings.NewReader(`{"id":1,"type":"login"}
+{"id":"2","type":"logout"}
+
+{"id":3,
+{"id":4,"type":"login"}`)
+
+	m := JTStools.NewMapToStruct()
+	var events []*Event
+	var errLines []int
+	err := m.TransformLines(r, func() interface{} { return &Event{} }, func(rec interface{}, line int, err error) bool {
+		if err != nil {
+			errLines = append(errLines, line)
+			return true
+		}
+		events = append(events, rec.(*Event))
+		return true
+	})
+	if err != nil {
+		t.Fatal("读取失败，err =", err)
+	}
+	if len(events) != 3 || events[1].ID != 2 || events[2].ID != 4 {
+		t.Fatal("转换结果不正确，events =", events)
+	}
+	if len(errLines) != 1 || errLines[0] != 4 {
+		t.Fatal("错误行号不正确，errLines =", errLines)
+	}
+}
+
+func TestTransformLinesStop(t *testing.T) {
+	r := strings.NewReader("{\"id\":1}\n{\"id\":2}\n{\"id\":3}\n")
+	n := 0
+	_ = JTStools.NewMapToStruct().TransformLines(r, func() interface{} { return &Event{} }, func(rec interface{}, line int, err error) bool {
+		n++
+		return line < 2
+	})
+	if n != 2 {
+		t.Fatal("fn返回false后应停止读取，n =", n)
+	}
+}
@@ -0,0 +1,67 @@
+package test13
+
+import (
+	JTStools "github.com/sajanray/GoJsonToStruct"
+	"testing"
+	"time"
+)
+
+type Config struct {
+	Name    string        `stm:"name"`
+	Port    int           `stm:"port"`
+	MaxSize uint64        `stm:"max_size"`
+	Ratio   float32       `stm:"ratio"`
+	Debug   bool          `stm:"debug"`
+	Since   string        `stm:"since"`
+	DB      DB            `stm:"db"`
+	Nodes   []Node        `stm:"nodes"`
+	Users   map[int]*Node `stm:"users"`
+}
+
+type DB struct {
+	Host string `stm:"host"`
+	Port *int   `stm:"port"`
+}
+
+type Node struct {
+	Name string `stm:"name"`
+	ID   int64  `stm:"id"`
+}
+
+func TestTransformYAML(t *testing.T) {
+	//gopkg.in/yaml.v2 解码后的数据
+	tree := map[interface{}]interface{}{
+		"name":     "api",
+		"port":     8080,
+		"max_size": uint64(18446744073709551615),
+		"ratio":    0.5,
+		"debug":    true,
+		"since":    time.Date(2024, 8, 21, 0, 0, 0, 0, time.UTC),
+		"db": map[interface{}]interface{}{
+			"host": "127.0.0.1",
+			"port": 3306,
+		},
+		"nodes": []interface{}{
+			map[interface{}]interface{}{"name": "a", "id": int64(1234567890123456789)},
+		},
+		"users": map[interface{}]interface{}{
+			1: map[interface{}]interface{}{"name": "admin"},
+		},
+	}
+
+	cfg := Config{}
+	m := JTStools.NewMapToStruct()
+	m.Tagkey = "stm"
+	if err := m.Transform(&cfg, tree); err != nil {
+		t.Fatal("yaml转struct失败，err =", err)
+	}
+	if cfg.Name != "api" || cfg.Port != 8080 || cfg.MaxSize != 18446744073709551615 || cfg.Ratio != 0.5 || !cfg.Debug {
+		t.Fatal("yaml转struct结果不正确，cfg =", cfg)
+	}
+	if cfg.Since != "2024-08-21T00:00:00Z" || cfg.DB.Port == nil || *cfg.DB.Port != 3306 {
+		t.Fatal("yaml转struct结果不正确，cfg =", cfg)
+	}
+	if len(cfg.Nodes) != 1 || cfg.Nodes[0].ID != 1234567890123456789 || cfg.Users[1] == nil || cfg.Users[1].Name != "admin" {
+		t.Fatal("yaml转struct结果不正确，cfg =", cfg)
+	}
+}
@@ -0,0 +1,146 @@
+package test14
+
+import (
+	"errors"
+	JTStools "github.com/sajanray/GoJsonToStruct"
+	"math"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type Student struct {
+	Name    string  `stm:"name"`
+	Age     int     `stm:"age"`
+	Height  float64 `stm:"height"`
+	Pass    bool    `stm:"pass"`
+	Created string  `stm:"created"`
+}
+
+type Class struct {
+	Name     string    `stm:"name"`
+	Students []Student `stm:"students"`
+}
+
+func TestMapStringString(t *testing.T) {
+	//redis HGETALL 的结果
+	src := map[string]string{"name": "admin", "age": "20", "height": "178.5", "pass": "true"}
+	stu := Student{}
+	m := JTStools.NewMapToStruct()
+	m.Tagkey = "stm"
+	if err := m.Transform(&stu, src); err != nil {
+		t.Fatal("map[string]string转struct失败，err =", err)
+	}
+	if stu.Name != "admin" || stu.Age != 20 || stu.Height != 178.5 || !stu.Pass {
+		t.Fatal("map[string]string转struct结果不正确，stu =", stu)
+	}
+}
+
+func TestTypedLeafValues(t *testing.T) {
+	created := time.Date(2024, 8, 21, 8, 0, 0, 0, time.UTC)
+	src := map[string]interface{}{"name": "admin", "age": int64(20), "height": 178, "pass": true, "created": created}
+	stu := Student{}
+	m := JTStools.NewMapToStruct()
+	m.Tagkey = "stm"
+	if err := m.Transform(&stu, src); err != nil {
+		t.Fatal("map转struct失败，err =", err)
+	}
+	if stu.Age != 20 || stu.Height != 178 || !stu.Pass || stu.Created != "2024-08-21T08:00:00Z" {
+		t.Fatal("map转struct结果不正确，stu =", stu)
+	}
+}
+
+func TestTypedSlices(t *testing.T) {
+	src := map[string]interface{}{
+		"name": "一班",
+		"students": []map[string]interface{}{
+			{"name": "a", "age": 18},
+			{"name": "b", "age": uint8(19)},
+		},
+	}
+	class := Class{}
+	m := JTStools.NewMapToStruct()
+	m.Tagkey = "stm"
+	if err := m.Transform(&class, src); err != nil {
+		t.Fatal("map转struct失败，err =", err)
+	}
+	if len(class.Students) != 2 || class.Students[1].Age != 19 {
+		t.Fatal("map转struct结果不正确，class =", class)
+	}
+}
+
+type Event struct {
+	ID      interface{}            `stm:"id"`
+	Created interface{}            `stm:"created"`
+	Ext     map[string]interface{} `stm:"ext"`
+	Raw     interface{}            `stm:"raw"`
+}
+
+func TestTypedLeafInterface(t *testing.T) {
+	created := time.Date(2024, 8, 21, 8, 0, 0, 0, time.UTC)
+	raw := map[string]interface{}{"a": 1.0}
+	src := map[string]interface{}{
+		"id":      int64(9007199254740993),
+		"created": created,
+		"ext":     map[string]interface{}{"id": uint64(9007199254740993)},
+		"raw":     raw,
+	}
+	ev := Event{}
+	m := JTStools.NewMapToStruct()
+	m.Tagkey = "stm"
+	if err := m.Transform(&ev, src); err != nil {
+		t.Fatal("map转struct失败，err =", err)
+	}
+	if ev.ID != int64(9007199254740993) || ev.Created != created || ev.Ext["id"] != uint64(9007199254740993) {
+		t.Fatalf("interface字段应保持原类型，ev = %#v", ev)
+	}
+	//json解码后结构的数据不复制
+	raw["b"] = 2.0
+	if len(ev.Raw.(map[string]interface{})) != 2 {
+		t.Fatal("json解码后结构的数据不应被复制，raw =", ev.Raw)
+	}
+}
+
+func TestTypedLeafErrorKind(t *testing.T) {
+	//错误信息中的源数据类型为Go数据源中值的原类型
+	stu := Student{}
+	m := JTStools.NewMapToStruct()
+	m.Tagkey = "stm"
+	m.ErrorPolicy = JTStools.ErrorPolicyCollect
+	err := m.Transform(&stu, map[string]interface{}{"age": uint64(math.MaxUint64)})
+
+	var te *JTStools.TransformError
+	if !errors.As(err, &te) || len(te.Errors) != 1 || te.Errors[0].SourceKind != reflect.Uint64 {
+		t.Fatal("期望age字段返回源数据类型为uint64的错误，err =", err)
+	}
+}
+
+type Meeting struct {
+	At     time.Time  `stm:"at"`
+	AtPtr  *time.Time `stm:"at_ptr"`
+	AtUTC  time.Time  `stm:"at_utc,tz=UTC"`
+	AtText string     `stm:"at_text"`
+}
+
+func TestTypedLeafTime(t *testing.T) {
+	//time.Time直接写入time.Time字段，保留时区
+	ny, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skip("缺少时区数据:", err)
+	}
+	at := time.Date(2024, 8, 21, 8, 0, 0, 123, ny)
+	src := map[string]interface{}{"at": at, "at_ptr": at, "at_utc": at, "at_text": at}
+	ev := Meeting{}
+	m := JTStools.NewMapToStruct()
+	m.Tagkey = "stm"
+	m.ErrorPolicy = JTStools.ErrorPolicyCollect
+	if err := m.Transform(&ev, src); err != nil {
+		t.Fatal("Go数据源转struct失败，err =", err)
+	}
+	if !ev.At.Equal(at) || ev.At.Location() != ny || ev.AtPtr == nil || ev.AtPtr.Location() != ny {
+		t.Fatal("time.Time字段应保留时区，ev =", ev)
+	}
+	if !ev.AtUTC.Equal(at) || ev.AtUTC.Location() != time.UTC || ev.AtText != at.Format(time.RFC3339Nano) {
+		t.Fatal("time.Time转换不正确，ev =", ev)
+	}
+}
@@ -0,0 +1,84 @@
+package test15
+
+import (
+	JTStools "github.com/sajanray/GoJsonToStruct"
+	"reflect"
+	"testing"
+)
+
+type Student struct {
+	Name    string  `stm:"name"`
+	Age     uint    `stm:"age"`
+	School  *School `stm:"school"`
+	Address *string `stm:"address"`
+	secret  string
+}
+
+type School struct {
+	Name     string              `stm:"name"`
+	Subject  []Subject           `stm:"subject"`
+	Subject3 map[int]*Subject    `stm:"subject3"`
+	Tags     map[string][]string `stm:"tags"`
+}
+
+type Subject struct {
+	Name  string
+	Score float32
+}
+
+func newStudent() Student {
+	return Student{
+		Name: "admin",
+		Age:  20,
+		School: &School{
+			Name:     "某某大学",
+			Subject:  []Subject{{Name: "语文", Score: 90}},
+			Subject3: map[int]*Subject{1: {Name: "美术", Score: 50}},
+			Tags:     map[string][]string{"level": {"985", "211"}},
+		},
+		secret: "x",
+	}
+}
+
+func TestStructToMap(t *testing.T) {
+	m := JTStools.NewMapToStruct()
+	m.Tagkey = "stm"
+	data, err := m.StructToMap(newStudent())
+	if err != nil {
+		t.Fatal("struct转map失败，err =", err)
+	}
+	expect := map[string]interface{}{
+		"name":    "admin",
+		"age":     uint(20),
+		"address": nil,
+		"school": map[string]interface{}{
+			"name":     "某某大学",
+			"subject":  []interface{}{map[string]interface{}{"Name": "语文", "Score": float32(90)}},
+			"subject3": map[string]interface{}{"1": map[string]interface{}{"Name": "美术", "Score": float32(50)}},
+			"tags":     map[string]interface{}{"level": []interface{}{"985", "211"}},
+		},
+	}
+	if !reflect.DeepEqual(data, expect) {
+		t.Fatal("struct转map结果不正确，data =", data)
+	}
+}
+
+func TestStructToJSON(t *testing.T) {
+	m := JTStools.NewMapToStruct()
+	m.Tagkey = "stm"
+	stu := newStudent()
+	data, err := m.StructToJSON(&stu)
+	if err != nil {
+		t.Fatal("struct转json失败，err =", err)
+	}
+	expect := `{"address":null,"age":20,"name":"admin","school":{"name":"某某大学","subject":[{"Name":"语文","Score":90}],"subject3":{"1":{"Name":"美术","Score":50}},"tags":{"level":["985","211"]}}}`
+	if string(data) != expect {
+		t.Fatal("struct转json结果不正确，json =", string(data))
+	}
+}
+
+func TestStructToMapNotStruct(t *testing.T) {
+	if _, err := JTStools.NewMapToStruct().StructToMap([]int{1}); err == nil {
+		t.Fatal("非结构体应返回错误")
+	}
+}
@@ -0,0 +1,129 @@
+package test16
+
+import (
+	JTStools "github.com/sajanray/GoJsonToStruct"
+	"testing"
+	"time"
+)
+
+type School struct {
+	Name     string         `stm:"name"`
+	Founded  time.Time      `stm:"founded,layout=2006-01-02"`
+	Opened   time.Time      `stm:"opened,layout=Jan 2, 2006,tz=Asia/Shanghai"`
+	Updated  time.Time      `stm:"updated"`
+	Created  *time.Time     `stm:"created,unit=ms"`
+	Lesson   time.Duration  `stm:"lesson"`
+	Break    time.Duration  `stm:"break,unit=s"`
+	Timeout  *time.Duration `stm:"timeout"`
+	Deadline time.Time      `stm:"deadline"`
+}
+
+func TestTime(t *testing.T) {
+	str := `{
+  "name": "某某大学",
+  "founded": "1970-10-01",
+  "opened": "Sep 1, 1971",
+  "updated": "2024-08-21T08:00:00+08:00",
+  "created": 1724198400000,
+  "lesson": "1h30m",
+  "break": 600,
+  "timeout": 1000000000,
+  "deadline": "1724198400"
+}`
+	school := School{}
+	m := JTStools.NewMapToStruct()
+	m.Tagkey = "stm"
+	m.ErrorPolicy = JTStools.ErrorPolicyCollect
+	if err := m.Transform(&school, str); err != nil {
+		t.Fatal("json转struct失败，err =", err)
+	}
+
+	shanghai, _ := time.LoadLocation("Asia/Shanghai")
+	if !school.Founded.Equal(time.Date(1970, 10, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Fatal("founded转换不正确:", school.Founded)
+	}
+	if !school.Opened.Equal(time.Date(1971, 9, 1, 0, 0, 0, 0, shanghai)) || school.Opened.Location().String() != "Asia/Shanghai" {
+		t.Fatal("opened转换不正确:", school.Opened)
+	}
+	if !school.Updated.Equal(time.Date(2024, 8, 21, 0, 0, 0, 0, time.UTC)) {
+		t.Fatal("updated转换不正确:", school.Updated)
+	}
+	if school.Created == nil || !school.Created.Equal(time.Unix(1724198400, 0)) {
+		t.Fatal("created转换不正确:", school.Created)
+	}
+	if !school.Deadline.Equal(time.Unix(1724198400, 0)) {
+		t.Fatal("deadline转换不正确:", school.Deadline)
+	}
+	if school.Lesson != 90*time.Minute || school.Break != 10*time.Minute || school.Timeout == nil || *school.Timeout != time.Second {
+		t.Fatal("duration转换不正确:", school.Lesson, school.Break, school.Timeout)
+	}
+}
+
+func TestTimeLayout(t *testing.T) {
+	school := School{}
+	m := JTStools.NewMapToStruct()
+	m.Tagkey = "stm"
+	m.ErrorPolicy = JTStools.ErrorPolicyCollect
+	m.TimeLayout = "2006-01-02 15:04:05"
+	m.TimeLocation = time.FixedZone("UTC+8", 8*3600)
+	if err := m.Transform(&school, `{"updated":"2024-08-21 08:00:00"}`); err != nil {
+		t.Fatal("json转struct失败，err =", err)
+	}
+	if !school.Updated.Equal(time.Date(2024, 8, 21, 0, 0, 0, 0, time.UTC)) {
+		t.Fatal("updated转换不正确:", school.Updated)
+	}
+
+	if err := m.Transform(&school, `{"founded":"1970/10/01","lesson":"abc"}`); err == nil {
+		t.Fatal("无效的时间应返回错误")
+	}
+}
+
+// UseNumber时数字是json.Number，按数字时间戳和时长处理
+func TestTimeUseNumber(t *testing.T) {
+	school := School{}
+	m := JTStools.NewMapToStruct()
+	m.Tagkey = "stm"
+	m.UseNumber = true
+	m.ErrorPolicy = JTStools.ErrorPolicyCollect
+	if err := m.Transform(&school, `{"updated":1724198400,"created":1724198400000,"break":600,"timeout":1500000000}`); err != nil {
+		t.Fatal("json转struct失败，err =", err)
+	}
+	if !school.Updated.Equal(time.Unix(1724198400, 0)) || school.Created == nil || !school.Created.Equal(time.Unix(1724198400, 0)) {
+		t.Fatal("时间转换不正确:", school.Updated, school.Created)
+	}
+	if school.Break != 10*time.Minute || school.Timeout == nil || *school.Timeout != 1500*time.Millisecond {
+		t.Fatal("时长转换不正确:", school.Break, school.Timeout)
+	}
+}
+
+// 整数时间戳按整数换算，不经过float64丢失精度
+func TestTimePrecision(t *testing.T) {
+	for _, useNumber := range []bool{false, true} {
+		school := School{}
+		m := JTStools.NewMapToStruct()
+		m.Tagkey = "stm"
+		m.UseNumber = useNumber
+		m.ErrorPolicy = JTStools.ErrorPolicyCollect
+		if err := m.Transform(&school, `{"created":1700000000123,"deadline":"1700000000.5","timeout":9007199254740993}`); err != nil {
+			t.Fatal("json转struct失败，err =", err)
+		}
+		if school.Created == nil || !school.Created.Equal(time.UnixMilli(1700000000123)) {
+			t.Fatalf("UseNumber = %v created转换不正确: %v", useNumber, school.Created)
+		}
+		if !school.Deadline.Equal(time.Unix(1700000000, 5e8)) {
+			t.Fatalf("UseNumber = %v deadline转换不正确: %v", useNumber, school.Deadline)
+		}
+		if useNumber && (school.Timeout == nil || *school.Timeout != 9007199254740993) {
+			t.Fatalf("timeout转换不正确: %v", school.Timeout)
+		}
+	}
+
+	//超出time.Duration范围时返回错误
+	school := School{}
+	m := JTStools.NewMapToStruct()
+	m.Tagkey = "stm"
+	m.ErrorPolicy = JTStools.ErrorPolicyCollect
+	if err := m.Transform(&school, `{"break":9223372037}`); err == nil || school.Break != 0 {
+		t.Fatal("超出范围的时长应返回错误，break =", school.Break)
+	}
+}
@@ -0,0 +1,104 @@
+package test17
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	JTStools "github.com/sajanray/GoJsonToStruct"
+	"math/big"
+	"net/netip"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// Money 金额，单位分
+type Money int64
+
+func (m *Money) UnmarshalText(text []byte) error {
+	f, err := strconv.ParseFloat(strings.TrimPrefix(string(text), "¥"), 64)
+	if err != nil {
+		return err
+	}
+	*m = Money(f * 100)
+	return nil
+}
+
+// Point 坐标，json格式为[x,y]
+type Point struct {
+	X, Y int
+}
+
+func (p *Point) UnmarshalJSON(data []byte) error {
+	var xy []int
+	if err := json.Unmarshal(data, &xy); err != nil {
+		return err
+	}
+	if len(xy) != 2 {
+		return errors.New("point must have 2 coordinates")
+	}
+	p.X, p.Y = xy[0], xy[1]
+	return nil
+}
+
+type Order struct {
+	ID      string           `stm:"id"`
+	Price   Money            `stm:"price"`
+	Balance *Money           `stm:"balance"`
+	Total   *big.Int         `stm:"total"`
+	IP      netip.Addr       `stm:"ip"`
+	Servers []netip.Addr     `stm:"servers"`
+	Fees    map[string]Money `stm:"fees"`
+	Points  []*Point         `stm:"points"`
+	Home    Point            `stm:"home"`
+}
+
+func TestUnmarshaler(t *testing.T) {
+	str := `{
+  "id": "A001",
+  "price": "¥12.5",
+  "balance": 3,
+  "total": 123456789012345678901234567890,
+  "ip": "192.168.1.1",
+  "servers": ["10.0.0.1", "10.0.0.2"],
+  "fees": {"ship": "¥5", "tax": 0.5},
+  "points": [[1, 2], [3, 4]],
+  "home": [5, 6]
+}`
+	order := Order{}
+	m := JTStools.NewMapToStruct()
+	m.Tagkey = "stm"
+	m.UseNumber = true
+	m.ErrorPolicy = JTStools.ErrorPolicyCollect
+	if err := m.Transform(&order, str); err != nil {
+		t.Fatal("json转struct失败，err =", err)
+	}
+	if order.Price != 1250 || order.Balance == nil || *order.Balance != 300 {
+		t.Fatal("Money转换不正确:", order.Price, order.Balance)
+	}
+	if order.Total == nil || order.Total.String() != "123456789012345678901234567890" {
+		t.Fatal("big.Int转换不正确:", order.Total)
+	}
+	if order.IP != netip.MustParseAddr("192.168.1.1") || len(order.Servers) != 2 || order.Servers[1] != netip.MustParseAddr("10.0.0.2") {
+		t.Fatal("netip.Addr转换不正确:", order.IP, order.Servers)
+	}
+	if order.Fees["ship"] != 500 || order.Fees["tax"] != 50 {
+		t.Fatal("map值转换不正确:", order.Fees)
+	}
+	if len(order.Points) != 2 || *order.Points[1] != (Point{3, 4}) || order.Home != (Point{5, 6}) {
+		t.Fatal("Point转换不正确:", fmt.Sprint(order.Points), order.Home)
+	}
+}
+
+func TestUnmarshalerError(t *testing.T) {
+	order := Order{}
+	m := JTStools.NewMapToStruct()
+	m.Tagkey = "stm"
+	m.ErrorPolicy = JTStools.ErrorPolicyCollect
+	err := m.Transform(&order, `{"ip":"999.1.1.1","home":[1]}`)
+
+	var te *JTStools.TransformError
+	if !errors.As(err, &te) || len(te.Errors) != 2 {
+		t.Fatal("期望返回2个字段错误，err =", err)
+	}
+}
@@ -0,0 +1,161 @@
+package test18
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	JTStools "github.com/sajanray/GoJsonToStruct"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+type Level int
+
+const (
+	LevelLow Level = iota + 1
+	LevelHigh
+)
+
+type Student struct {
+	Name     string    `stm:"name"`
+	Pass     bool      `stm:"pass"`
+	Vip      *bool     `stm:"vip"`
+	Level    Level     `stm:"level"`
+	Levels   []Level   `stm:"levels"`
+	Birthday time.Time `stm:"birthday"`
+}
+
+func init() {
+	JTStools.RegisterConverter(reflect.TypeOf(Level(0)), func(src interface{}) (interface{}, error) {
+		switch fmt.Sprint(src) {
+		case "low", "1":
+			return LevelLow, nil
+		case "high", "2":
+			return LevelHigh, nil
+		}
+		return nil, fmt.Errorf("invalid level %v", src)
+	})
+}
+
+func newMapToStruct() *JTStools.MapToStruct {
+	m := JTStools.NewMapToStruct()
+	m.Tagkey = "stm"
+	m.ErrorPolicy = JTStools.ErrorPolicyCollect
+	m.RegisterKindConverter(reflect.String, reflect.TypeOf(true), func(src interface{}) (interface{}, error) {
+		switch strings.ToUpper(src.(string)) {
+		case "Y":
+			return true, nil
+		case "N":
+			return false, nil
+		}
+		return nil, errors.New("flag must be Y or N")
+	})
+	m.RegisterConverter(reflect.TypeOf(time.Time{}), func(src interface{}) (interface{}, error) {
+		return time.Parse("20060102", src.(string))
+	})
+	return m
+}
+
+func TestConverter(t *testing.T) {
+	stu := Student{}
+	m := newMapToStruct()
+	str := `{"name":"admin","pass":"Y","vip":"n","level":"high","levels":["low",2],"birthday":"20000101"}`
+	if err := m.Transform(&stu, str); err != nil {
+		t.Fatal("json转struct失败，err =", err)
+	}
+	if !stu.Pass || stu.Vip == nil || *stu.Vip || stu.Level != LevelHigh {
+		t.Fatal("json转struct结果不正确，stu =", stu)
+	}
+	if len(stu.Levels) != 2 || stu.Levels[0] != LevelLow || stu.Levels[1] != LevelHigh {
+		t.Fatal("切片元素转换不正确，levels =", stu.Levels)
+	}
+	if !stu.Birthday.Equal(time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Fatal("birthday转换不正确:", stu.Birthday)
+	}
+}
+
+func TestConverterKind(t *testing.T) {
+	//源数据不是字符串时使用内置规则
+	stu := Student{}
+	if err := newMapToStruct().Transform(&stu, `{"pass":true}`); err != nil || !stu.Pass {
+		t.Fatal("json转struct失败，err =", err)
+	}
+}
+
+func TestConverterError(t *testing.T) {
+	stu := Student{}
+	err := newMapToStruct().Transform(&stu, `{"pass":"X","level":"middle"}`)
+
+	var te *JTStools.TransformError
+	if !errors.As(err, &te) || len(te.Errors) != 2 {
+		t.Fatal("期望返回2个字段错误，err =", err)
+	}
+}
+
+type Flag bool
+
+type Score float64
+
+type Record struct {
+	Flag  Flag   `stm:"f"`
+	Flags []Flag `stm:"fs"`
+	Score Score  `stm:"score"`
+	Level *Level `stm:"level"`
+}
+
+func newRecordMapper(useNumber bool) *JTStools.MapToStruct {
+	m := JTStools.NewMapToStruct()
+	m.Tagkey = "stm"
+	m.ErrorPolicy = JTStools.ErrorPolicyCollect
+	m.UseNumber = useNumber
+	m.RegisterKindConverter(reflect.String, reflect.TypeOf(Flag(false)), func(src interface{}) (interface{}, error) {
+		return Flag(src.(string) == "Y"), nil
+	})
+	m.RegisterKindConverter(reflect.Int, reflect.TypeOf(Flag(false)), func(src interface{}) (interface{}, error) {
+		return Flag(src.(int) > 0), nil
+	})
+	return m
+}
+
+func TestConverterKindFallback(t *testing.T) {
+	//没有匹配源数据类型的转换函数时使用内置规则
+	for _, useNumber := range []bool{false, true} {
+		rec := Record{}
+		if err := newRecordMapper(useNumber).Transform(&rec, `{"f":1,"fs":[0,"Y"]}`); err != nil {
+			t.Fatal("json转struct失败，err =", err)
+		}
+		if !rec.Flag || len(rec.Flags) != 2 || rec.Flags[0] || !rec.Flags[1] {
+			t.Fatalf("UseNumber = %v 转换结果不正确，rec = %+v", useNumber, rec)
+		}
+	}
+
+	//Go数据源中的int按reflect.Int匹配并原样传入，指针指向的类型同样适用
+	rec := Record{}
+	if err := newRecordMapper(false).Transform(&rec, map[string]interface{}{"f": 5, "fs": []int{0}, "level": 2}); err != nil {
+		t.Fatal("Go数据源转struct失败，err =", err)
+	}
+	if !rec.Flag || len(rec.Flags) != 1 || rec.Flags[0] || rec.Level == nil || *rec.Level != LevelHigh {
+		t.Fatalf("Go数据源转换结果不正确，rec = %+v", rec)
+	}
+}
+
+func TestConverterNumber(t *testing.T) {
+	//json中的数字按reflect.Float64匹配，UseNumber为true时传入json.Number
+	var got []interface{}
+	for _, useNumber := range []bool{false, true} {
+		m := newRecordMapper(useNumber)
+		m.RegisterKindConverter(reflect.Float64, reflect.TypeOf(Score(0)), func(src interface{}) (interface{}, error) {
+			got = append(got, src)
+			return Score(100), nil
+		})
+		rec := Record{}
+		if err := m.Transform(&rec, `{"score":99.5}`); err != nil || rec.Score != 100 {
+			t.Fatalf("转换结果不正确，err = %v rec = %+v", err, rec)
+		}
+	}
+	if !reflect.DeepEqual(got, []interface{}{99.5, json.Number("99.5")}) {
+		t.Fatalf("转换函数收到的值不正确，got = %#v", got)
+	}
+}
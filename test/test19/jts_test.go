@@ -0,0 +1,66 @@
+package test19
+
+import (
+	JTStools "github.com/sajanray/GoJsonToStruct"
+	"testing"
+)
+
+type Status string
+type Score float64
+type Age uint8
+type Flag bool
+type ClassID int
+type Grade string
+
+type Subject struct {
+	Name  Status `stm:"name"`
+	Score Score  `stm:"score"`
+}
+
+type Subjects []Subject
+
+type Student struct {
+	Name     string              `stm:"name"`
+	Status   Status              `stm:"status"`
+	Age      Age                 `stm:"age"`
+	Pass     Flag                `stm:"pass"`
+	Score    Score               `stm:"score"`
+	PStatus  *Status             `stm:"pstatus"`
+	PScore   *Score              `stm:"pscore"`
+	PAge     *Age                `stm:"page"`
+	Subjects Subjects            `stm:"subjects"`
+	Classes  map[ClassID]Subject `stm:"classes"`
+	Grades   map[Grade]*Subject  `stm:"grades"`
+}
+
+func TestNamedTypes(t *testing.T) {
+	str := `{
+  "name": "admin",
+  "status": "active",
+  "age": "20",
+  "pass": true,
+  "score": 90.5,
+  "pstatus": "locked",
+  "pscore": "60",
+  "page": 18,
+  "subjects": [{"name": "语文", "score": 90}],
+  "classes": {"1": {"name": "数学", "score": "80"}},
+  "grades": {"A": {"name": "美术", "score": 70}}
+}`
+	stu := Student{}
+	m := JTStools.NewMapToStruct()
+	m.Tagkey = "stm"
+	m.ErrorPolicy = JTStools.ErrorPolicyCollect
+	if err := m.Transform(&stu, str); err != nil {
+		t.Fatal("json转struct失败，err =", err)
+	}
+	if stu.Status != "active" || stu.Age != 20 || !stu.Pass || stu.Score != 90.5 {
+		t.Fatal("json转struct结果不正确，stu =", stu)
+	}
+	if stu.PStatus == nil || *stu.PStatus != "locked" || stu.PScore == nil || *stu.PScore != 60 || stu.PAge == nil || *stu.PAge != 18 {
+		t.Fatal("指针字段转换不正确，stu =", stu)
+	}
+	if len(stu.Subjects) != 1 || stu.Subjects[0].Name != "语文" || stu.Classes[1].Score != 80 || stu.Grades["A"].Name != "美术" {
+		t.Fatal("切片、map转换不正确，stu =", stu)
+	}
+}
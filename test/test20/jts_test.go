@@ -0,0 +1,99 @@
+package test20
+
+import (
+	"errors"
+	JTStools "github.com/sajanray/GoJsonToStruct"
+	"reflect"
+	"testing"
+)
+
+type Student struct {
+	Tags    []string                   `stm:"tags"`
+	Scores  []int                      `stm:"scores"`
+	Heights []float64                  `stm:"heights"`
+	Ages    map[string]int             `stm:"ages"`
+	Groups  map[string][]string        `stm:"groups"`
+	Matrix  [][]int                    `stm:"matrix"`
+	Flags   map[string]map[string]bool `stm:"flags"`
+	Ptrs    []*int                     `stm:"ptrs"`
+	Extra   map[string]interface{}     `stm:"extra"`
+	Items   []interface{}              `stm:"items"`
+	Index   map[int]string             `stm:"index"`
+}
+
+func TestPrimitiveElements(t *testing.T) {
+	str := `{
+  "tags": ["a", "b", 3],
+  "scores": ["1", 2, "3"],
+  "heights": [1.5, "2.5"],
+  "ages": {"a": "18", "b": 19},
+  "groups": {"x": ["1", "2"], "y": []},
+  "matrix": [[1, "2"], [3]],
+  "flags": {"a": {"x": true, "y": "0"}},
+  "ptrs": [1, "2"],
+  "extra": {"k": "v", "n": 1},
+  "items": ["a", 1, {"b": true}],
+  "index": ["x", "y"]
+}`
+	stu := Student{}
+	m := JTStools.NewMapToStruct()
+	m.Tagkey = "stm"
+	m.ErrorPolicy = JTStools.ErrorPolicyCollect
+	if err := m.Transform(&stu, str); err != nil {
+		t.Fatal("json转struct失败，err =", err)
+	}
+	one, two := 1, 2
+	expect := Student{
+		Tags:    []string{"a", "b", "3"},
+		Scores:  []int{1, 2, 3},
+		Heights: []float64{1.5, 2.5},
+		Ages:    map[string]int{"a": 18, "b": 19},
+		Groups:  map[string][]string{"x": {"1", "2"}, "y": {}},
+		Matrix:  [][]int{{1, 2}, {3}},
+		Flags:   map[string]map[string]bool{"a": {"x": true, "y": false}},
+		Ptrs:    []*int{&one, &two},
+		Extra:   map[string]interface{}{"k": "v", "n": float64(1)},
+		Items:   []interface{}{"a", float64(1), map[string]interface{}{"b": true}},
+		Index:   map[int]string{0: "x", 1: "y"},
+	}
+	if !reflect.DeepEqual(stu, expect) {
+		t.Fatalf("json转struct结果不正确，stu = %+v", stu)
+	}
+}
+
+func TestPrimitiveElementErrors(t *testing.T) {
+	stu := Student{}
+	m := JTStools.NewMapToStruct()
+	m.Tagkey = "stm"
+	m.ErrorPolicy = JTStools.ErrorPolicyCollect
+	err := m.Transform(&stu, `{"scores":[1,"x",3],"matrix":[[1],["y"]]}`)
+
+	var te *JTStools.TransformError
+	if !errors.As(err, &te) || len(te.Errors) != 2 || te.Errors[0].Path != "scores[1]" || te.Errors[1].Path != "matrix[1][0]" {
+		t.Fatal("错误路径不正确，err =", err)
+	}
+}
+
+func TestPrimitiveRoot(t *testing.T) {
+	var ids []uint64
+	if err := JTStools.NewMapToStruct().Transform(&ids, `["1", 2, 3]`); err != nil || len(ids) != 3 || ids[0] != 1 {
+		t.Fatal("json转[]uint64失败，err =", err, "ids =", ids)
+	}
+}
+
+func TestEmptySlice(t *testing.T) {
+	//[]映射成空切片，null映射成nil，与encoding/json一致
+	stu := Student{}
+	m := JTStools.NewMapToStruct()
+	m.Tagkey = "stm"
+	if err := m.Transform(&stu, `{"tags":[],"scores":null}`); err != nil {
+		t.Fatal("json转struct失败，err =", err)
+	}
+	if stu.Tags == nil || len(stu.Tags) != 0 || stu.Scores != nil {
+		t.Fatalf("空切片转换不正确，stu = %+v", stu)
+	}
+	data, _ := m.StructToMap(stu)
+	if !reflect.DeepEqual(data["tags"], []interface{}{}) || data["scores"] != nil {
+		t.Fatalf("struct转map结果不正确，data = %#v", data)
+	}
+}
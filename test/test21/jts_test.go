@@ -0,0 +1,101 @@
+package test21
+
+import (
+	"errors"
+	JTStools "github.com/sajanray/GoJsonToStruct"
+	"reflect"
+	"testing"
+)
+
+type Point struct {
+	X int `stm:"x"`
+	Y int `stm:"y"`
+}
+
+type Place struct {
+	Coord  [3]float64 `stm:"coord"`
+	ID     [4]byte    `stm:"id"`
+	Corner [2]Point   `stm:"corner"`
+	Center [1]*Point  `stm:"center"`
+	Grid   [2][2]int  `stm:"grid"`
+	Tags   [2]string  `stm:"tags,array=strict"`
+}
+
+func TestArrayField(t *testing.T) {
+	str := `{
+  "coord": [1.5, "2", 3],
+  "id": [1, 2, 3, 4],
+  "corner": [{"x": 1, "y": 2}, {"x": 3, "y": 4}],
+  "center": [{"x": 5, "y": 6}],
+  "grid": [[1, 2], [3, 4]],
+  "tags": ["a", "b"]
+}`
+	place := Place{}
+	m := JTStools.NewMapToStruct()
+	m.Tagkey = "stm"
+	m.ErrorPolicy = JTStools.ErrorPolicyCollect
+	if err := m.Transform(&place, str); err != nil {
+		t.Fatal("json转struct失败，err =", err)
+	}
+	expect := Place{
+		Coord:  [3]float64{1.5, 2, 3},
+		ID:     [4]byte{1, 2, 3, 4},
+		Corner: [2]Point{{1, 2}, {3, 4}},
+		Center: [1]*Point{{5, 6}},
+		Grid:   [2][2]int{{1, 2}, {3, 4}},
+		Tags:   [2]string{"a", "b"},
+	}
+	if !reflect.DeepEqual(place, expect) {
+		t.Fatalf("json转struct结果不正确，place = %+v", place)
+	}
+}
+
+func TestArrayPolicy(t *testing.T) {
+	str := `{"coord": [1, 2, 3, 4], "grid": [[1], [2, 3, 4]]}`
+	cases := []struct {
+		policy JTStools.ArrayPolicy
+		coord  [3]float64
+		grid   [2][2]int
+		paths  []string
+	}{
+		{JTStools.ArrayPolicyLoose, [3]float64{1, 2, 3}, [2][2]int{{1, 0}, {2, 3}}, nil},
+		{JTStools.ArrayPolicyTruncate, [3]float64{1, 2, 3}, [2][2]int{{0, 0}, {2, 3}}, []string{"grid[0]"}},
+		{JTStools.ArrayPolicyZeroPad, [3]float64{}, [2][2]int{{1, 0}, {0, 0}}, []string{"coord", "grid[1]"}},
+		{JTStools.ArrayPolicyStrict, [3]float64{}, [2][2]int{}, []string{"coord", "grid[0]", "grid[1]"}},
+	}
+	for _, c := range cases {
+		place := Place{}
+		m := JTStools.NewMapToStruct()
+		m.Tagkey = "stm"
+		m.ErrorPolicy = JTStools.ErrorPolicyCollect
+		m.ArrayPolicy = c.policy
+		err := m.Transform(&place, str)
+		if place.Coord != c.coord || place.Grid != c.grid {
+			t.Fatalf("策略%d转换结果不正确，place = %+v", c.policy, place)
+		}
+		var paths []string
+		var te *JTStools.TransformError
+		if errors.As(err, &te) {
+			for _, fe := range te.Errors {
+				if !errors.Is(fe, JTStools.ErrArrayLength) {
+					t.Fatal("错误类型不正确，err =", fe)
+				}
+				paths = append(paths, fe.Path)
+			}
+		}
+		if !reflect.DeepEqual(paths, c.paths) {
+			t.Fatalf("策略%d错误路径不正确，err = %v", c.policy, err)
+		}
+	}
+}
+
+func TestArrayTagPolicy(t *testing.T) {
+	place := Place{}
+	m := JTStools.NewMapToStruct()
+	m.Tagkey = "stm"
+	m.ErrorPolicy = JTStools.ErrorPolicyCollect
+	err := m.Transform(&place, `{"tags": ["a"]}`)
+	if !errors.Is(err, JTStools.ErrArrayLength) {
+		t.Fatal("标签选项array=strict未生效，err =", err)
+	}
+}
@@ -0,0 +1,96 @@
+package test22
+
+import (
+	"errors"
+	JTStools "github.com/sajanray/GoJsonToStruct"
+	"testing"
+	"time"
+)
+
+type School struct {
+	Name string `stm:"name"`
+}
+
+type Student struct {
+	Name     *string                `stm:"name"`
+	Active   *bool                  `stm:"active"`
+	Age      **int                  `stm:"age"`
+	Score    ***float64             `stm:"score"`
+	Tags     *[]string              `stm:"tags"`
+	Ages     *map[string]int        `stm:"ages"`
+	Birthday *time.Time             `stm:"birthday"`
+	School   **School               `stm:"school"`
+	Coord    *[2]int                `stm:"coord"`
+	Extra    *interface{}           `stm:"extra"`
+	Schools  map[string]**School    `stm:"schools"`
+	Classes  []**School             `stm:"classes"`
+	Nums     [2]**int               `stm:"nums"`
+	Nested   *map[string]*[]*string `stm:"nested"`
+}
+
+func TestPointer(t *testing.T) {
+	str := `{
+  "name": "Tom",
+  "active": "true",
+  "age": "18",
+  "score": 99.5,
+  "tags": ["a", 1],
+  "ages": {"a": "1"},
+  "birthday": "2006-01-02T15:04:05Z",
+  "school": {"name": "s1"},
+  "coord": [1, 2],
+  "extra": {"k": "v"},
+  "schools": {"a": {"name": "s2"}},
+  "classes": [{"name": "s3"}],
+  "nums": [1, "2"],
+  "nested": {"x": ["y"]}
+}`
+	stu := Student{}
+	m := JTStools.NewMapToStruct()
+	m.Tagkey = "stm"
+	m.ErrorPolicy = JTStools.ErrorPolicyCollect
+	if err := m.Transform(&stu, str); err != nil {
+		t.Fatal("json转struct失败，err =", err)
+	}
+	if *stu.Name != "Tom" || !*stu.Active || **stu.Age != 18 || ***stu.Score != 99.5 {
+		t.Fatalf("基础类型指针转换不正确，stu = %+v", stu)
+	}
+	if (*stu.Tags)[1] != "1" || (*stu.Ages)["a"] != 1 || stu.Birthday.Year() != 2006 || *stu.Coord != [2]int{1, 2} {
+		t.Fatalf("切片、map、时间、数组指针转换不正确，stu = %+v", stu)
+	}
+	if (**stu.School).Name != "s1" || (**stu.Schools["a"]).Name != "s2" || (**stu.Classes[0]).Name != "s3" {
+		t.Fatalf("结构体多级指针转换不正确，stu = %+v", stu)
+	}
+	if **stu.Nums[0] != 1 || **stu.Nums[1] != 2 || *(*(*stu.Nested)["x"])[0] != "y" {
+		t.Fatalf("元素多级指针转换不正确，stu = %+v", stu)
+	}
+	if (*stu.Extra).(map[string]interface{})["k"] != "v" {
+		t.Fatalf("interface指针转换不正确，stu = %+v", stu)
+	}
+}
+
+func TestPointerFail(t *testing.T) {
+	stu := Student{}
+	m := JTStools.NewMapToStruct()
+	m.Tagkey = "stm"
+	m.ErrorPolicy = JTStools.ErrorPolicyCollect
+	err := m.Transform(&stu, `{"age":"x","active":"yes","school":"s1","tags":["a",{}]}`)
+
+	var te *JTStools.TransformError
+	if !errors.As(err, &te) || len(te.Errors) != 4 {
+		t.Fatal("错误数量不正确，err =", err)
+	}
+	//转换失败的指针保持为nil，转换失败的元素不append，不影响上层指针
+	if stu.Age != nil || stu.Active != nil || stu.School != nil || stu.Tags == nil || len(*stu.Tags) != 1 {
+		t.Fatalf("转换失败的指针不正确，stu = %+v", stu)
+	}
+}
+
+func TestPointerRoot(t *testing.T) {
+	var school **School
+	m := JTStools.NewMapToStruct()
+	m.Tagkey = "stm"
+	if err := m.Transform(&school, `{"name":"s1"}`); err != nil || (**school).Name != "s1" {
+		t.Fatal("json转**School失败，err =", err)
+	}
+}
@@ -0,0 +1,102 @@
+package test23
+
+import (
+	"errors"
+	JTStools "github.com/sajanray/GoJsonToStruct"
+	"testing"
+	"time"
+)
+
+type School struct {
+	Name string `stm:"name"`
+}
+
+type Student struct {
+	Name     string                 `stm:"name"`
+	Age      int                    `stm:"age"`
+	Address  *string                `stm:"address"`
+	Tags     []string               `stm:"tags"`
+	Scores   map[string]int         `stm:"scores"`
+	Extra    interface{}            `stm:"extra"`
+	School   School                 `stm:"school"`
+	Birthday time.Time              `stm:"birthday"`
+	Mobile   string                 `stm:"mobile,null=zero"`
+	Items    []int                  `stm:"items"`
+	Props    map[string]interface{} `stm:"props"`
+}
+
+const nullJson = `{"name":null,"age":null,"address":null,"tags":null,"scores":null,"extra":null,
+"school":null,"birthday":null,"mobile":null,"items":[1,null,3],"props":{"a":null}}`
+
+func newStudent() Student {
+	address := "beijing"
+	return Student{
+		Name:     "admin",
+		Age:      20,
+		Address:  &address,
+		Tags:     []string{"a"},
+		Scores:   map[string]int{"a": 1},
+		Extra:    "x",
+		School:   School{Name: "s1"},
+		Birthday: time.Date(2006, 1, 2, 0, 0, 0, 0, time.UTC),
+		Mobile:   "130",
+	}
+}
+
+func TestNullIgnore(t *testing.T) {
+	stu := newStudent()
+	m := JTStools.NewMapToStruct()
+	m.Tagkey = "stm"
+	if err := m.Transform(&stu, nullJson); err != nil {
+		t.Fatal("json转struct失败，err =", err)
+	}
+	if stu.Address != nil || stu.Tags != nil || stu.Scores != nil || stu.Extra != nil {
+		t.Fatalf("指针、切片、map、interface应设置为nil，stu = %+v", stu)
+	}
+	if stu.Name != "admin" || stu.Age != 20 || stu.School.Name != "s1" || stu.Birthday.Year() != 2006 || stu.Mobile != "" {
+		t.Fatalf("值类型字段应保持原值，stu = %+v", stu)
+	}
+	if len(stu.Items) != 3 || stu.Items[1] != 0 || stu.Props["a"] != nil {
+		t.Fatalf("null元素转换不正确，stu = %+v", stu)
+	}
+}
+
+func TestNullZero(t *testing.T) {
+	stu := newStudent()
+	m := JTStools.NewMapToStruct()
+	m.Tagkey = "stm"
+	m.NullPolicy = JTStools.NullPolicyZero
+	if err := m.Transform(&stu, nullJson); err != nil {
+		t.Fatal("json转struct失败，err =", err)
+	}
+	if stu.Name != "" || stu.Age != 0 || stu.School.Name != "" || !stu.Birthday.IsZero() {
+		t.Fatalf("值类型字段应设置为零值，stu = %+v", stu)
+	}
+}
+
+func TestNullStrict(t *testing.T) {
+	stu := newStudent()
+	m := JTStools.NewMapToStruct()
+	m.Tagkey = "stm"
+	m.NullPolicy = JTStools.NullPolicyStrict
+	err := m.Transform(&stu, nullJson)
+
+	var te *JTStools.TransformError
+	if !errors.As(err, &te) {
+		t.Fatal("期望返回*TransformError，err =", err)
+	}
+	paths := map[string]bool{}
+	for _, fe := range te.Errors {
+		if !errors.Is(fe, JTStools.ErrNull) || fe.Error() != fe.Path+": null value" {
+			t.Fatal("错误类型不正确，err =", fe)
+		}
+		paths[fe.Path] = true
+	}
+	//mobile 使用标签选项null=zero
+	if len(paths) != 5 || !paths["name"] || !paths["age"] || !paths["school"] || !paths["birthday"] || !paths["items[1]"] {
+		t.Fatal("错误路径不正确，err =", err)
+	}
+	if stu.Mobile != "" || stu.Address != nil {
+		t.Fatalf("可以设置的字段应继续转换，stu = %+v", stu)
+	}
+}
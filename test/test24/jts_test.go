@@ -0,0 +1,124 @@
+package test24
+
+import (
+	"encoding/json"
+	JTStools "github.com/sajanray/GoJsonToStruct"
+	"reflect"
+	"testing"
+)
+
+type School struct {
+	Name string `stm:"name"`
+	City string `stm:"city"`
+}
+
+type Patch struct {
+	Name    JTStools.Optional[string]   `stm:"name"`
+	Age     JTStools.Optional[int]      `stm:"age"`
+	Mobile  JTStools.Optional[string]   `stm:"mobile"`
+	Score   JTStools.Optional[*float64] `stm:"score"`
+	School  JTStools.Optional[School]   `stm:"school"`
+	Tags    []JTStools.Optional[int]    `stm:"tags"`
+	Address string                      `stm:"address"`
+}
+
+func TestOptional(t *testing.T) {
+	patch := Patch{}
+	m := JTStools.NewMapToStruct()
+	m.Tagkey = "stm"
+	err := m.Transform(&patch, `{"age":"0","mobile":null,"score":9.5,"school":{"name":"s1"},"tags":[1,null]}`)
+	if err != nil {
+		t.Fatal("json转struct失败，err =", err)
+	}
+	if patch.Name.Present {
+		t.Fatal("未传的字段Present应为false，name =", patch.Name)
+	}
+	if age, ok := patch.Age.Get(); !ok || age != 0 {
+		t.Fatal("传了0的字段转换不正确，age =", patch.Age)
+	}
+	if !patch.Mobile.Present || !patch.Mobile.Null {
+		t.Fatal("null字段转换不正确，mobile =", patch.Mobile)
+	}
+	if score, ok := patch.Score.Get(); !ok || *score != 9.5 {
+		t.Fatal("指针字段转换不正确，score =", patch.Score)
+	}
+	if school, ok := patch.School.Get(); !ok || school.Name != "s1" {
+		t.Fatal("结构体字段转换不正确，school =", patch.School)
+	}
+	if len(patch.Tags) != 2 || !patch.Tags[0].Present || !patch.Tags[1].Null {
+		t.Fatal("切片元素转换不正确，tags =", patch.Tags)
+	}
+
+	//未传的字段不出现在map中，null字段为nil
+	data, err := m.StructToMap(patch)
+	if err != nil {
+		t.Fatal("struct转map失败，err =", err)
+	}
+	expect := map[string]interface{}{
+		"age":     0,
+		"mobile":  nil,
+		"score":   9.5,
+		"school":  map[string]interface{}{"name": "s1", "city": ""},
+		"tags":    []interface{}{patch.Tags[0], patch.Tags[1]},
+		"address": "",
+	}
+	if !reflect.DeepEqual(data, expect) {
+		t.Fatalf("struct转map结果不正确，data = %#v", data)
+	}
+	b, _ := json.Marshal(patch.Tags)
+	if string(b) != "[1,null]" {
+		t.Fatal("Optional编码不正确，tags =", string(b))
+	}
+}
+
+type PtrPatch struct {
+	Age   *JTStools.Optional[int] `stm:"age"`
+	Level *JTStools.Optional[int] `stm:"level"`
+}
+
+func TestOptionalPtrToMap(t *testing.T) {
+	//nil的*Optional[T]编码成null，不为nil时与Optional[T]一致
+	patch := PtrPatch{Level: &JTStools.Optional[int]{Value: 2, Present: true}}
+	m := JTStools.NewMapToStruct()
+	m.Tagkey = "stm"
+	data, err := m.StructToMap(patch)
+	if err != nil {
+		t.Fatal("struct转map失败，err =", err)
+	}
+	if !reflect.DeepEqual(data, map[string]interface{}{"age": nil, "level": 2}) {
+		t.Fatalf("struct转map结果不正确，data = %#v", data)
+	}
+}
+
+// 源数据中有该字段即为出现，与转换是否成功无关，Optional和Presence一致
+func TestOptionalFail(t *testing.T) {
+	for _, str := range []string{`{"age":"x"}`, `{"age":""}`} {
+		patch := Patch{Age: JTStools.Optional[int]{Value: 5}}
+		m := JTStools.NewMapToStruct()
+		m.Tagkey = "stm"
+		m.Transform(&patch, str)
+		if !patch.Age.Present || patch.Age.Null || patch.Age.Value != 0 || !m.Presence().Has("age") {
+			t.Fatal("转换失败的字段Present应为true且值为零值，age =", patch.Age, "presence =", m.Presence())
+		}
+		if _, ok := patch.Age.Get(); !ok {
+			t.Fatal("Get应返回true，age =", patch.Age)
+		}
+	}
+}
+
+func TestPresence(t *testing.T) {
+	patch := Patch{}
+	m := JTStools.NewMapToStruct()
+	m.Tagkey = "stm"
+	if err := m.Transform(&patch, `{"age":0,"address":null,"school":{"city":"beijing"}}`); err != nil {
+		t.Fatal("json转struct失败，err =", err)
+	}
+	p := m.Presence()
+	expect := JTStools.Presence{"age": false, "address": true, "school": false, "school.city": false}
+	if !reflect.DeepEqual(p, expect) {
+		t.Fatal("Presence不正确，presence =", p)
+	}
+	if !p.Has("address") || !p.IsNull("address") || p.Has("name") || p.Has("school.name") {
+		t.Fatal("Presence查询不正确，presence =", p)
+	}
+}
@@ -0,0 +1,107 @@
+package test25
+
+import (
+	"encoding/json"
+	"errors"
+	JTStools "github.com/sajanray/GoJsonToStruct"
+	"reflect"
+	"testing"
+)
+
+type Base struct {
+	ID      int    `json:"id"`
+	Created string `json:"created,omitempty"`
+}
+
+type Meta struct {
+	Source string `json:"source"`
+}
+
+type School struct {
+	Name string `json:"name"`
+}
+
+type Student struct {
+	Base
+	*Meta
+	Name     string  `json:"name,omitempty"`
+	Password string  `json:"-"`
+	Dash     string  `json:"-,"`
+	Age      int     `json:"age,string"`
+	Nickname string  `json:"nickname,string"`
+	Score    float64 `json:",omitempty"`
+	School   School  `json:"school,omitempty"`
+	Extra    School  `json:"extra,inline"`
+}
+
+const str = `{"id":1,"source":"api","name":"admin","Password":"x","-":"dash","age":"18","nickname":"\"tom\"",
+"Score":99.5,"school":{"name":"s1"}}`
+
+func TestJsonTag(t *testing.T) {
+	stu := Student{}
+	if err := JTStools.NewMapToStruct().Transform(&stu, str); err != nil {
+		t.Fatal("json转struct失败，err =", err)
+	}
+	//与json.Unmarshal结果一致，inline不是encoding/json的选项单独检查
+	stu2 := Student{}
+	if err := json.Unmarshal([]byte(str), &stu2); err != nil {
+		t.Fatal("json.Unmarshal失败，err =", err)
+	}
+	stu2.Extra.Name = "admin"
+	if !reflect.DeepEqual(stu, stu2) {
+		t.Fatalf("json转struct结果不正确\nstu  = %+v\nstu2 = %+v", stu, stu2)
+	}
+}
+
+func TestJsonTagStructToMap(t *testing.T) {
+	stu := Student{Base: Base{ID: 1}, Password: "x", Dash: "dash", Age: 18, Nickname: "tom", Extra: School{Name: "admin"}}
+	data, err := JTStools.NewMapToStruct().StructToMap(stu)
+	if err != nil {
+		t.Fatal("struct转map失败，err =", err)
+	}
+	expect := map[string]interface{}{
+		"id":       1,
+		"-":        "dash",
+		"age":      "18",
+		"nickname": `"tom"`,
+		"school":   map[string]interface{}{"name": ""},
+		"name":     "admin",
+	}
+	if !reflect.DeepEqual(data, expect) {
+		t.Fatalf("struct转map结果不正确，data = %#v", data)
+	}
+}
+
+type Account struct {
+	Name   string `stm:"name,required"`
+	Mobile string `stm:"mobile,required"`
+	Level  int    `stm:"level,default=1"`
+	Active bool   `stm:"active,default=true"`
+	Tags   string `stm:"tags,default=1,2"`
+	Colors string `stm:"colors,default=red,blue,omitempty"`
+	Size   string `stm:"size,default=small, large"`
+}
+
+func TestRequiredDefault(t *testing.T) {
+	acc := Account{}
+	m := JTStools.NewMapToStruct()
+	m.Tagkey = "stm"
+	err := m.Transform(&acc, `{"name":"admin","active":false}`)
+
+	var te *JTStools.TransformError
+	if !errors.As(err, &te) || len(te.Errors) != 1 || te.Errors[0].Path != "mobile" || !errors.Is(err, JTStools.ErrRequired) ||
+		err.Error() != "mobile: required field missing" {
+		t.Fatal("期望mobile字段返回ErrRequired，err =", err)
+	}
+	if acc.Name != "admin" || acc.Level != 1 || acc.Active || acc.Tags != "1,2" || acc.Colors != "red,blue" || acc.Size != "small, large" {
+		t.Fatalf("默认值不正确，acc = %+v", acc)
+	}
+	//omitempty在带逗号的默认值之后仍是选项
+	data, _ := m.StructToMap(Account{})
+	if _, ok := data["colors"]; ok || data["size"] != "" {
+		t.Fatal("标签选项解析不正确，data =", data)
+	}
+	if m.Presence().Has("level") {
+		t.Fatal("使用默认值的字段不应出现在Presence中")
+	}
+}
@@ -0,0 +1,73 @@
+package test26
+
+import (
+	"errors"
+	JTStools "github.com/sajanray/GoJsonToStruct"
+	"reflect"
+	"testing"
+)
+
+type User struct {
+	UserName string
+	UserID   int
+	HTTPPort int
+	Mobile   string `stm:"phone"`
+}
+
+func TestNameMatcher(t *testing.T) {
+	cases := []struct {
+		name    string
+		matcher JTStools.NameMatcher
+		str     string
+	}{
+		{"case", JTStools.MatchCaseInsensitive, `{"username":"admin","USERID":1,"httpport":80}`},
+		{"snake", JTStools.MatchSnakeCase, `{"user_name":"admin","user_id":1,"http_port":80}`},
+		{"camel", JTStools.MatchCamelCase, `{"userName":"admin","userId":1,"httpPort":80}`},
+		{"camel2", JTStools.MatchCamelCase, `{"userName":"admin","userID":1,"httpPort":80}`},
+		{"kebab", JTStools.MatchKebabCase, `{"user-name":"admin","user-id":1,"http-port":80}`},
+		{"screaming", JTStools.MatchScreamingSnake, `{"USER_NAME":"admin","USER_ID":1,"HTTP_PORT":80}`},
+		{"any", JTStools.MatchAny(JTStools.MatchSnakeCase, JTStools.MatchKebabCase), `{"user_name":"admin","user-id":1,"http_port":80}`},
+	}
+	for _, c := range cases {
+		user := User{}
+		m := JTStools.NewMapToStruct()
+		m.Tagkey = "stm"
+		m.NameMatcher = c.matcher
+		if err := m.Transform(&user, c.str); err != nil {
+			t.Fatal(c.name, "json转struct失败，err =", err)
+		}
+		if user.UserName != "admin" || user.UserID != 1 || user.HTTPPort != 80 {
+			t.Fatalf("%s 匹配结果不正确，user = %+v", c.name, user)
+		}
+	}
+}
+
+func TestNameMatcherExact(t *testing.T) {
+	user := User{}
+	m := JTStools.NewMapToStruct()
+	m.Tagkey = "stm"
+	m.NameMatcher = JTStools.MatchCaseInsensitive
+	//字段名精确匹配优先，有标签名的字段不按规则匹配
+	if err := m.Transform(&user, `{"UserName":"admin","username":"x","MOBILE":"130"}`); err != nil {
+		t.Fatal("json转struct失败，err =", err)
+	}
+	if user.UserName != "admin" || user.Mobile != "" {
+		t.Fatalf("匹配结果不正确，user = %+v", user)
+	}
+}
+
+func TestNameMatcherAmbiguous(t *testing.T) {
+	user := User{}
+	m := JTStools.NewMapToStruct()
+	m.Tagkey = "stm"
+	m.NameMatcher = JTStools.MatchCaseInsensitive
+	err := m.Transform(&user, `{"userName":"a","username":"b","userid":1}`)
+
+	var te *JTStools.TransformError
+	if !errors.As(err, &te) || len(te.Errors) != 1 || te.Errors[0].Path != "UserName" || !errors.Is(err, JTStools.ErrAmbiguousKey) {
+		t.Fatal("期望UserName字段返回ErrAmbiguousKey，err =", err)
+	}
+	if te.Errors[0].Error() != "UserName: ambiguous key: userName, username" || te.Errors[0].SourceKind != reflect.Invalid || user.UserName != "" || user.UserID != 1 {
+		t.Fatalf("匹配结果不正确，err = %v user = %+v", err, user)
+	}
+}
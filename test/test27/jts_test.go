@@ -0,0 +1,51 @@
+package test27
+
+import (
+	JTStools "github.com/sajanray/GoJsonToStruct"
+	"reflect"
+	"testing"
+)
+
+type Student struct {
+	Name     string `stm:"name" json:"student_name"`
+	Age      int    `json:"age,string"`
+	Mobile   string `stm:"phone"`
+	Password string `json:"-"`
+	Secret   string `stm:"-" json:"secret"`
+	UserID   int
+}
+
+func TestTagkeys(t *testing.T) {
+	stu := Student{}
+	m := JTStools.NewMapToStruct()
+	m.Tagkeys = []string{"stm", "json"}
+	m.NameMatcher = JTStools.MatchSnakeCase
+	err := m.Transform(&stu, `{"name":"admin","student_name":"x","age":"18","phone":"130","Password":"p","secret":"s","user_id":1}`)
+	if err != nil {
+		t.Fatal("json转struct失败，err =", err)
+	}
+	expect := Student{Name: "admin", Age: 18, Mobile: "130", UserID: 1}
+	if stu != expect {
+		t.Fatalf("json转struct结果不正确，stu = %+v", stu)
+	}
+
+	data, err := m.StructToMap(expect)
+	if err != nil {
+		t.Fatal("struct转map失败，err =", err)
+	}
+	if !reflect.DeepEqual(data, map[string]interface{}{"name": "admin", "age": "18", "phone": "130", "UserID": 1}) {
+		t.Fatalf("struct转map结果不正确，data = %#v", data)
+	}
+}
+
+func TestTagkeysOrder(t *testing.T) {
+	stu := Student{}
+	m := JTStools.NewMapToStruct()
+	m.Tagkeys = []string{"json", "stm"}
+	if err := m.Transform(&stu, `{"name":"admin","student_name":"x","phone":"130","secret":"s"}`); err != nil {
+		t.Fatal("json转struct失败，err =", err)
+	}
+	if stu.Name != "x" || stu.Mobile != "130" || stu.Secret != "s" {
+		t.Fatalf("json转struct结果不正确，stu = %+v", stu)
+	}
+}
@@ -0,0 +1,66 @@
+package test28
+
+import (
+	"errors"
+	JTStools "github.com/sajanray/GoJsonToStruct"
+	"testing"
+)
+
+type Student struct {
+	ID      string   `stm:"data.id"`
+	Name    string   `stm:"data.attributes.name"`
+	Age     int      `stm:"data.attributes.age"`
+	Mobile  string   `stm:"data.attributes.profile.contact.mobile"`
+	First   int      `stm:"data.items[0].id"`
+	Cell    int      `stm:"data.matrix[1][0]"`
+	Tags    []string `stm:"data.attributes.tags"`
+	Dotted  string   `stm:"a.b"`
+	Missing string   `stm:"data.items[5].id"`
+	Bad     string   `stm:"data..id"`
+}
+
+const str = `{
+  "a.b": "dotted",
+  "data": {
+    "id": "u1",
+    "attributes": {
+      "name": "admin",
+      "age": "18",
+      "profile": {"contact": {"mobile": "130"}},
+      "tags": ["a", "b"]
+    },
+    "items": [{"id": 7}, {"id": 8}],
+    "matrix": [[1], [2, 3]]
+  }
+}`
+
+func TestNestedPath(t *testing.T) {
+	stu := Student{}
+	m := JTStools.NewMapToStruct()
+	m.Tagkey = "stm"
+	if err := m.Transform(&stu, str); err != nil {
+		t.Fatal("json转struct失败，err =", err)
+	}
+	if stu.ID != "u1" || stu.Name != "admin" || stu.Age != 18 || stu.Mobile != "130" || stu.First != 7 || stu.Cell != 2 {
+		t.Fatalf("json转struct结果不正确，stu = %+v", stu)
+	}
+	if len(stu.Tags) != 2 || stu.Dotted != "dotted" || stu.Missing != "" || stu.Bad != "" {
+		t.Fatalf("json转struct结果不正确，stu = %+v", stu)
+	}
+	if !m.Presence().Has("data.attributes.age") || m.Presence().Has("data.items[5].id") {
+		t.Fatal("Presence不正确，presence =", m.Presence())
+	}
+}
+
+func TestNestedPathError(t *testing.T) {
+	stu := Student{}
+	m := JTStools.NewMapToStruct()
+	m.Tagkey = "stm"
+	m.ErrorPolicy = JTStools.ErrorPolicyCollect
+	err := m.Transform(&stu, `{"data":{"attributes":{"age":"x"},"items":"x"}}`)
+
+	var te *JTStools.TransformError
+	if !errors.As(err, &te) || len(te.Errors) != 1 || te.Errors[0].Path != "data.attributes.age" {
+		t.Fatal("期望data.attributes.age字段返回错误，err =", err)
+	}
+}
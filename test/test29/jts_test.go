@@ -0,0 +1,91 @@
+package test29
+
+import (
+	"errors"
+	JTStools "github.com/sajanray/GoJsonToStruct"
+	"reflect"
+	"testing"
+)
+
+type Student struct {
+	Name   string `stm:"name|username"`
+	Mobile string `stm:"mobile|phone|tel"`
+	Email  string `stm:"email|mail,exclusive"`
+	City   string `stm:"address.city|city"`
+	Age    int    `stm:"age|years,required"`
+}
+
+func TestAlias(t *testing.T) {
+	stu := Student{}
+	m := JTStools.NewMapToStruct()
+	m.Tagkey = "stm"
+	err := m.Transform(&stu, `{"username":"admin","tel":"131","phone":"130","mail":"a@b.c","address":{"city":"beijing"},"years":"18"}`)
+	if err != nil {
+		t.Fatal("json转struct失败，err =", err)
+	}
+	expect := Student{Name: "admin", Mobile: "130", Email: "a@b.c", City: "beijing", Age: 18}
+	if stu != expect {
+		t.Fatalf("json转struct结果不正确，stu = %+v", stu)
+	}
+	//Presence使用第一个key，与源数据实际使用的key无关
+	p := m.Presence()
+	if !p.Has("mobile") || p.Has("phone") || !p.Has("address.city") || !p.Has("age") || p.Has("years") {
+		t.Fatal("Presence不正确，presence =", m.Presence())
+	}
+
+	//struct转map使用第一个key
+	data, _ := m.StructToMap(expect)
+	if !reflect.DeepEqual(data, map[string]interface{}{"name": "admin", "mobile": "130", "email": "a@b.c", "address.city": "beijing", "age": 18}) {
+		t.Fatalf("struct转map结果不正确，data = %#v", data)
+	}
+}
+
+func TestAliasExclusive(t *testing.T) {
+	stu := Student{}
+	m := JTStools.NewMapToStruct()
+	m.Tagkey = "stm"
+	err := m.Transform(&stu, `{"email":"a@b.c","mail":"x@y.z","age":1}`)
+
+	var te *JTStools.TransformError
+	if !errors.As(err, &te) || len(te.Errors) != 1 || te.Errors[0].Path != "email" || !errors.Is(err, JTStools.ErrAmbiguousKey) {
+		t.Fatal("期望email字段返回ErrAmbiguousKey，err =", err)
+	}
+	if te.Errors[0].Error() != "email: ambiguous key: email, mail" || te.Errors[0].SourceKind != reflect.Invalid || stu.Email != "" {
+		t.Fatalf("转换结果不正确，err = %v stu = %+v", err, stu)
+	}
+}
+
+type Contact struct {
+	Email string `stm:"email|mail,exclusive,default=none"`
+}
+
+func TestAliasExclusiveDefault(t *testing.T) {
+	c := Contact{}
+	m := JTStools.NewMapToStruct()
+	m.Tagkey = "stm"
+	err := m.Transform(&c, `{"email":"a@b.c","mail":"x@y.z"}`)
+
+	//存在多个key时不使用默认值
+	var te *JTStools.TransformError
+	if !errors.As(err, &te) || len(te.Errors) != 1 || te.Errors[0].Path != "email" || !errors.Is(err, JTStools.ErrAmbiguousKey) || c.Email != "" {
+		t.Fatalf("期望email字段返回ErrAmbiguousKey且不使用默认值，err = %v c = %+v", err, c)
+	}
+
+	//没有key时使用默认值
+	c = Contact{}
+	if err = m.Transform(&c, `{}`); err != nil || c.Email != "none" {
+		t.Fatalf("默认值不正确，err = %v c = %+v", err, c)
+	}
+}
+
+func TestAliasRequired(t *testing.T) {
+	stu := Student{}
+	m := JTStools.NewMapToStruct()
+	m.Tagkey = "stm"
+	err := m.Transform(&stu, `{"name":"admin"}`)
+
+	var te *JTStools.TransformError
+	if !errors.As(err, &te) || len(te.Errors) != 1 || te.Errors[0].Path != "age" || !errors.Is(err, JTStools.ErrRequired) {
+		t.Fatal("期望age字段返回ErrRequired，err =", err)
+	}
+}
@@ -0,0 +1,76 @@
+package test5
+
+import (
+	"errors"
+	JTStools "github.com/sajanray/GoJsonToStruct"
+	"testing"
+)
+
+type Student struct {
+	Name   string `stm:"name"`
+	School School `stm:"school"`
+}
+
+type School struct {
+	Name    string    `stm:"name"`
+	Subject []Subject `stm:"subject"`
+}
+
+type Subject struct {
+	Name  string
+	Score float32
+}
+
+func TestTransformError(t *testing.T) {
+	stu := Student{}
+	str := `{"name":"admin","school":{"name":"某某大学","subject":[{"Name":"语文","Score":90},{"Name":"美术","Score":50},100]}}`
+
+	m := JTStools.NewMapToStruct()
+	m.Tagkey = "stm"
+	m.ErrorPolicy = JTStools.ErrorPolicyCollect
+	err := m.Transform(&stu, str)
+	if err == nil || m.Success {
+		t.Fatal("期望转换失败")
+	}
+
+	var te *JTStools.TransformError
+	if !errors.As(err, &te) {
+		t.Fatal("错误类型不是*TransformError，err =", err)
+	}
+	if len(te.Errors) != 1 {
+		t.Fatal("期望1个字段错误，实际:", te.Errors)
+	}
+	if te.Errors[0].Path != "school.subject[2]" {
+		t.Fatal("错误路径不正确:", te.Errors[0].Path)
+	}
+	if len(stu.School.Subject) != 2 {
+		t.Fatal("转换失败的元素不应append:", stu.School.Subject)
+	}
+	if m.GetErrmsg() != err.Error() {
+		t.Fatal("GetErrmsg与返回的错误不一致:", m.GetErrmsg())
+	}
+	t.Log("err =", err)
+}
+
+// 默认的ErrorPolicyIgnore下元素转换失败只输出日志，不返回错误
+func TestTransformErrorIgnore(t *testing.T) {
+	stu := Student{}
+	str := `{"name":"admin","school":{"name":"某某大学","subject":[{"Name":"语文","Score":90},100]}}`
+
+	m := JTStools.NewMapToStruct()
+	m.Tagkey = "stm"
+	if err := m.Transform(&stu, str); err != nil || !m.Success {
+		t.Fatal("忽略模式不应返回错误，err =", err)
+	}
+	if len(stu.School.Subject) != 1 || stu.School.Subject[0].Name != "语文" {
+		t.Fatal("转换失败的元素不应append:", stu.School.Subject)
+	}
+}
+
+func TestTransformErrorNotPtr(t *testing.T) {
+	stu := Student{}
+	err := JTStools.NewMapToStruct().Transform(stu, `{"name":"admin"}`)
+	if err == nil || err.Error() != "param destStructData is not ptr" {
+		t.Fatal("期望返回非指针错误，err =", err)
+	}
+}
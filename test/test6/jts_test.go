@@ -0,0 +1,119 @@
+package test6
+
+import (
+	"errors"
+	JTStools "github.com/sajanray/GoJsonToStruct"
+	"testing"
+)
+
+type Student struct {
+	Name   string  `stm:"name"`
+	Age    int     `stm:"age"`
+	Height uint    `stm:"height"`
+	Score  float64 `stm:"score"`
+	Pass   *int    `stm:"pass"`
+}
+
+const str = `{"name":"admin","age":"abc","height":"178","score":"x90","pass":"1"}`
+
+func TestErrorPolicyIgnore(t *testing.T) {
+	stu := Student{}
+	m := JTStools.NewMapToStruct()
+	m.Tagkey = "stm"
+	err := m.Transform(&stu, str)
+	if err != nil {
+		t.Fatal("忽略模式不应返回错误，err =", err)
+	}
+	if stu.Height != 178 || stu.Pass == nil || *stu.Pass != 1 {
+		t.Fatal("其他字段转换失败，stu =", stu)
+	}
+}
+
+func TestErrorPolicyCollect(t *testing.T) {
+	stu := Student{}
+	m := JTStools.NewMapToStruct()
+	m.Tagkey = "stm"
+	m.ErrorPolicy = JTStools.ErrorPolicyCollect
+	err := m.Transform(&stu, str)
+
+	var te *JTStools.TransformError
+	if !errors.As(err, &te) || len(te.Errors) != 2 {
+		t.Fatal("期望返回2个字段错误，err =", err)
+	}
+	paths := map[string]bool{}
+	for _, fe := range te.Errors {
+		paths[fe.Path] = true
+	}
+	if !paths["age"] || !paths["score"] {
+		t.Fatal("错误路径不正确，err =", err)
+	}
+	if stu.Height != 178 || stu.Pass == nil || *stu.Pass != 1 {
+		t.Fatal("其他字段转换失败，stu =", stu)
+	}
+}
+
+func TestErrorPolicyFailFast(t *testing.T) {
+	stu := Student{}
+	m := JTStools.NewMapToStruct()
+	m.Tagkey = "stm"
+	m.ErrorPolicy = JTStools.ErrorPolicyFailFast
+	err := m.Transform(&stu, str)
+
+	var te *JTStools.TransformError
+	if !errors.As(err, &te) || len(te.Errors) != 1 || te.Errors[0].Path != "age" {
+		t.Fatal("期望在age字段停止，err =", err)
+	}
+	if stu.Height != 0 {
+		t.Fatal("快速失败后不应继续转换，stu =", stu)
+	}
+}
+
+type Range struct {
+	I8    int8    `stm:"i8"`
+	U8    uint8   `stm:"u8"`
+	U16   uint16  `stm:"u16"`
+	Int   int     `stm:"int"`
+	Uint  uint    `stm:"uint"`
+	F32   float32 `stm:"f32"`
+	Valid int16   `stm:"valid"`
+}
+
+func TestErrorPolicyOverflow(t *testing.T) {
+	r := Range{}
+	m := JTStools.NewMapToStruct()
+	m.Tagkey = "stm"
+	m.ErrorPolicy = JTStools.ErrorPolicyCollect
+	err := m.Transform(&r, `{"i8":200,"u8":"300","u16":70000,"int":18.7,"uint":-1,"f32":1e39,"valid":"-32768"}`)
+
+	var te *JTStools.TransformError
+	if !errors.As(err, &te) || len(te.Errors) != 6 {
+		t.Fatal("期望返回6个字段错误，err =", err)
+	}
+	if r != (Range{Valid: -32768}) {
+		t.Fatal("超出范围的字段不应被设置，r =", r)
+	}
+}
+
+type Flags struct {
+	B1    bool   `stm:"b1"`
+	B2    bool   `stm:"b2"`
+	B3    bool   `stm:"b3"`
+	Price string `stm:"price"`
+}
+
+func TestErrorPolicyBoolString(t *testing.T) {
+	//布尔值只接受0和1，数字转字符串保留小数
+	f := Flags{}
+	m := JTStools.NewMapToStruct()
+	m.Tagkey = "stm"
+	m.ErrorPolicy = JTStools.ErrorPolicyCollect
+	err := m.Transform(&f, `{"b1":256,"b2":1.5,"b3":1,"price":3.75}`)
+
+	var te *JTStools.TransformError
+	if !errors.As(err, &te) || len(te.Errors) != 2 || te.Errors[0].Path != "b1" || te.Errors[1].Path != "b2" {
+		t.Fatal("期望b1、b2字段返回错误，err =", err)
+	}
+	if f != (Flags{B3: true, Price: "3.75"}) {
+		t.Fatal("转换结果不正确，f =", f)
+	}
+}
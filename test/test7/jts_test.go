@@ -0,0 +1,45 @@
+package test7
+
+import (
+	"bytes"
+	"encoding/json"
+	JTStools "github.com/sajanray/GoJsonToStruct"
+	"log/slog"
+	"testing"
+)
+
+type Student struct {
+	Name string `stm:"name"`
+	Age  int    `stm:"age"`
+}
+
+func TestSlogLogger(t *testing.T) {
+	var buf bytes.Buffer
+	stu := Student{}
+	m := JTStools.NewMapToStruct()
+	m.Tagkey = "stm"
+	m.Logger = JTStools.NewSlogLogger(slog.New(slog.NewJSONHandler(&buf, nil)))
+	if err := m.Transform(&stu, `{"name":"admin","age":"abc"}`); err != nil {
+		t.Fatal("忽略模式不应返回错误，err =", err)
+	}
+
+	var record map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &record); err != nil {
+		t.Fatal("日志不是json格式:", buf.String())
+	}
+	if record["path"] != "age" || record["source_kind"] != "string" || record["target_kind"] != "int" {
+		t.Fatal("日志属性不正确:", buf.String())
+	}
+}
+
+func TestNopLogger(t *testing.T) {
+	stu := Student{}
+	m := JTStools.NewMapToStruct()
+	m.Tagkey = "stm"
+	m.Debug = true
+	m.Logger = JTStools.NopLogger()
+	m.Transform(&stu, `{"name":"admin","age":"abc"}`)
+	if stu.Name != "admin" {
+		t.Fatal("json转struct失败，stu =", stu)
+	}
+}
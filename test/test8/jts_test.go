@@ -0,0 +1,51 @@
+package test8
+
+import (
+	"errors"
+	JTStools "github.com/sajanray/GoJsonToStruct"
+	"reflect"
+	"testing"
+)
+
+type Address string
+
+type Student struct {
+	Name    string  `stm:"name"`
+	Age     int     `stm:"age"`
+	Address Address `stm:"address"`
+}
+
+func TestRecoverRuntimeError(t *testing.T) {
+	stu := Student{}
+	m := JTStools.NewMapToStruct()
+	m.Tagkey = "stm"
+	m.CaptureStack = true
+	//转换函数中的类型断言会panic
+	m.RegisterConverter(reflect.TypeOf(Address("")), func(src interface{}) (interface{}, error) {
+		return src.(map[string]interface{})["city"], nil
+	})
+	err := m.Transform(&stu, `{"name":"admin","address":"beijing","age":20}`)
+
+	var te *JTStools.TransformError
+	if !errors.As(err, &te) || len(te.Errors) != 1 || te.Errors[0].Path != "address" {
+		t.Fatal("期望address字段返回错误，err =", err)
+	}
+	var pe *JTStools.PanicError
+	if !errors.As(err, &pe) || len(pe.Stack) == 0 {
+		t.Fatal("期望返回带调用栈的*PanicError，err =", err)
+	}
+	if stu.Name != "admin" || stu.Age != 20 {
+		t.Fatal("其他字段应继续转换，stu =", stu)
+	}
+}
+
+func TestNotStruct(t *testing.T) {
+	//目标不是结构体时返回参数错误，不依赖捕获panic
+	n := 0
+	err := JTStools.NewMapToStruct().Transform(&n, `{"name":"admin"}`)
+
+	var pe *JTStools.PanicError
+	if err == nil || err.Error() != "param destStructData is not struct" || errors.As(err, &pe) {
+		t.Fatal("期望返回param destStructData is not struct，err =", err)
+	}
+}
@@ -0,0 +1,71 @@
+package test9
+
+import (
+	JTStools "github.com/sajanray/GoJsonToStruct"
+	"testing"
+)
+
+type Student struct {
+	Name string `json:"name"`
+	Age  int    `json:"age"`
+}
+
+const arr = `[{"name":"a","age":"18"},{"name":"b","age":20}]`
+
+func TestSliceRoot(t *testing.T) {
+	var stus []Student
+	if err := JTStools.NewMapToStruct().Transform(&stus, arr); err != nil {
+		t.Fatal("json转[]Student失败，err =", err)
+	}
+	if len(stus) != 2 || stus[0].Name != "a" || stus[0].Age != 18 || stus[1].Age != 20 {
+		t.Fatal("json转[]Student结果不正确，stus =", stus)
+	}
+}
+
+func TestPtrSliceRoot(t *testing.T) {
+	var stus []*Student
+	if err := JTStools.NewMapToStruct().Transform(&stus, arr); err != nil {
+		t.Fatal("json转[]*Student失败，err =", err)
+	}
+	if len(stus) != 2 || stus[0].Name != "a" || stus[1].Name != "b" {
+		t.Fatal("json转[]*Student结果不正确，stus =", stus)
+	}
+}
+
+func TestSliceRootReplace(t *testing.T) {
+	ids := []int{9, 9, 9}
+	if err := JTStools.NewMapToStruct().Transform(&ids, `[1,2]`); err != nil {
+		t.Fatal("json转[]int失败，err =", err)
+	}
+	if len(ids) != 2 || ids[0] != 1 || ids[1] != 2 {
+		t.Fatal("原有元素应被替换，ids =", ids)
+	}
+}
+
+func TestArrayRoot(t *testing.T) {
+	var stus [2]Student
+	if err := JTStools.NewMapToStruct().Transform(&stus, arr); err != nil {
+		t.Fatal("json转[2]Student失败，err =", err)
+	}
+	if stus[0].Name != "a" || stus[1].Age != 20 {
+		t.Fatal("json转[2]Student结果不正确，stus =", stus)
+	}
+}
+
+func TestMapRoot(t *testing.T) {
+	var stus map[int]*Student
+	if err := JTStools.NewMapToStruct().Transform(&stus, `{"1":{"name":"a"},"2":{"name":"b"}}`); err != nil {
+		t.Fatal("json转map[int]*Student失败，err =", err)
+	}
+	if len(stus) != 2 || stus[1].Name != "a" || stus[2].Name != "b" {
+		t.Fatal("json转map[int]*Student结果不正确，stus =", stus)
+	}
+}
+
+func TestSliceRootError(t *testing.T) {
+	var stus []Student
+	err := JTStools.NewMapToStruct().Transform(&stus, `{"name":"a"}`)
+	if err == nil {
+		t.Fatal("对象转切片应返回错误")
+	}
+}